@@ -0,0 +1,77 @@
+// confschema renders configuration template and detects drift of deployed configuration
+// against JSON Schema exported by config.SchemaOf(dest).JSON().
+//
+// Usage:
+//
+//	confschema template -schema schema.json
+//	confschema diff -schema schema.json -config deployed.yaml
+//
+// diff exits with status 1 if any unknown key, missing required key or type mismatch is found.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/mytoko2796/sdk-go/stdlib/config"
+)
+
+const usage = `Usage:
+	confschema template -schema schema.json
+	confschema diff -schema schema.json -config deployed.yaml
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	fs := flag.NewFlagSet(os.Args[1], flag.ExitOnError)
+	schemaPath := fs.String("schema", "", "path of JSON Schema exported by config.SchemaOf")
+	configPath := fs.String("config", "", "path of deployed configuration file")
+	fs.Parse(os.Args[2:])
+
+	if *schemaPath == "" {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	bs, err := ioutil.ReadFile(*schemaPath)
+	if err != nil {
+		fail(err)
+	}
+	schema, err := config.ParseSchema(bs)
+	if err != nil {
+		fail(err)
+	}
+
+	switch os.Args[1] {
+	case "template":
+		os.Stdout.Write(schema.YAMLTemplate())
+	case "diff":
+		if *configPath == "" {
+			fmt.Fprint(os.Stderr, usage)
+			os.Exit(2)
+		}
+		drifts, err := schema.DiffFile(*configPath)
+		if err != nil {
+			fail(err)
+		}
+		for _, d := range drifts {
+			fmt.Println(d)
+		}
+		if len(drifts) > 0 {
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(2)
+}
@@ -1,3 +1,3 @@
 module github.com/mytoko2796/sdk-go
 
-go 1.15
+go 1.19
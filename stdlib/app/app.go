@@ -0,0 +1,231 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	errors "github.com/mytoko2796/sdk-go/stdlib/error"
+	log "github.com/mytoko2796/sdk-go/stdlib/logger"
+)
+
+const (
+	infoApp string = `App:`
+
+	_OK     string = `[OK]`
+	_FAILED string = `[FAILED]`
+
+	defaultStartTimeout = 30 * time.Second
+	defaultStopTimeout  = 5 * time.Second
+)
+
+// Component is a part of app with its own lifecycle e.g. database, config or http server
+type Component interface {
+	// Start returns once component is ready, dependencies are already started
+	Start(ctx context.Context) error
+	// Stop releases component resources, dependents are already stopped
+	Stop(ctx context.Context) error
+}
+
+// App is a registry of components. Each App is independent, so an app may run
+// several servers or a test may create as many apps as it needs.
+type App interface {
+	// Register adds component depending on already or later registered components named in deps,
+	// it returns ErrAlreadyStarted once Start is called until Stop
+	Register(name string, c Component, deps ...string) error
+	// Start starts components in dependency order. If a component fails to start,
+	// components already started are stopped. Components are started without holding
+	// registry lock, so they may call Order but Register fails.
+	Start(ctx context.Context) error
+	// Stop stops started components in reverse order of start and returns the first error
+	Stop(ctx context.Context) error
+	// Order returns component names in start order
+	Order() ([]string, error)
+}
+
+type Options struct {
+	// StartTimeout wait period of each component start, default 30s
+	StartTimeout time.Duration
+	// StopTimeout wait period of each component stop, default 5s
+	StopTimeout time.Duration
+}
+
+type component struct {
+	name string
+	c    Component
+	deps []string
+}
+
+type app struct {
+	logger     log.Logger
+	opt        Options
+	mu         *sync.Mutex
+	components []*component
+	byName     map[string]*component
+	started    []*component
+	running    bool
+}
+
+// Init returns an empty component registry
+func Init(logger log.Logger, opt Options) App {
+	if opt.StartTimeout <= 0 {
+		opt.StartTimeout = defaultStartTimeout
+	}
+	if opt.StopTimeout <= 0 {
+		opt.StopTimeout = defaultStopTimeout
+	}
+	return &app{
+		logger: logger,
+		opt:    opt,
+		mu:     &sync.Mutex{},
+		byName: make(map[string]*component),
+	}
+}
+
+func (a *app) Register(name string, c Component, deps ...string) error {
+	if c == nil {
+		return errors.NewWithCode(EcodeComponentIsNil, `Component %s is Nil`, name)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.running {
+		return ErrAlreadyStarted
+	}
+	if _, ok := a.byName[name]; ok {
+		return errors.NewWithCode(EcodeComponentAlreadyRegistered, `Component %s is already registered`, name)
+	}
+	comp := &component{name: name, c: c, deps: deps}
+	a.components = append(a.components, comp)
+	a.byName[name] = comp
+	return nil
+}
+
+func (a *app) Order() ([]string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	order, err := a.order()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(order))
+	for _, c := range order {
+		names = append(names, c.name)
+	}
+	return names, nil
+}
+
+// order sorts components topologically, independent components keep order of
+// registration. It requires mu to be locked.
+func (a *app) order() ([]*component, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	marks := make(map[string]int, len(a.components))
+	order := make([]*component, 0, len(a.components))
+	var visit func(c *component, path []string) error
+	visit = func(c *component, path []string) error {
+		switch marks[c.name] {
+		case visited:
+			return nil
+		case visiting:
+			return errors.NewWithCode(EcodeDependencyCycle, `Dependency cycle %v`, append(path, c.name))
+		}
+		marks[c.name] = visiting
+		for _, name := range c.deps {
+			dep, ok := a.byName[name]
+			if !ok {
+				return errors.NewWithCode(EcodeUnknownDependency, `Unknown dependency %s of component %s`, name, c.name)
+			}
+			if err := visit(dep, append(path, c.name)); err != nil {
+				return err
+			}
+		}
+		marks[c.name] = visited
+		order = append(order, c)
+		return nil
+	}
+	for _, c := range a.components {
+		if err := visit(c, nil); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+func (a *app) Start(ctx context.Context) error {
+	a.mu.Lock()
+	if a.running {
+		a.mu.Unlock()
+		return ErrAlreadyStarted
+	}
+	order, err := a.order()
+	if err != nil {
+		a.mu.Unlock()
+		return err
+	}
+	a.running = true
+	a.mu.Unlock()
+
+	for _, c := range order {
+		start := time.Now()
+		if err := a.call(ctx, a.opt.StartTimeout, c.c.Start); err != nil {
+			err = errors.WrapWithCode(err, EcodeStartFailed, `%s [%s] failed to start`, _FAILED, c.name)
+			a.logger.Error(err)
+			// ctx may be done already, each stop is bounded by StopTimeout instead
+			a.stop(context.Background())
+			return err
+		}
+		a.mu.Lock()
+		a.started = append(a.started, c)
+		a.mu.Unlock()
+		a.logger.Info(_OK, infoApp, fmt.Sprintf("[%s] started in %s", c.name, time.Since(start)))
+	}
+	return nil
+}
+
+func (a *app) Stop(ctx context.Context) error {
+	return a.stop(ctx)
+}
+
+// stop stops started components in reverse order, components are called without holding mu.
+// Component is logged before it stops and only failures after, since logger may be the component.
+func (a *app) stop(ctx context.Context) error {
+	a.mu.Lock()
+	started := a.started
+	a.started = nil
+	a.running = false
+	a.mu.Unlock()
+
+	var result error
+	for i := len(started) - 1; i >= 0; i-- {
+		c := started[i]
+		a.logger.Info(_OK, infoApp, fmt.Sprintf("[%s] stopping", c.name))
+		if err := a.call(ctx, a.opt.StopTimeout, c.c.Stop); err != nil {
+			err = errors.WrapWithCode(err, EcodeStopFailed, `%s [%s] failed to stop`, _FAILED, c.name)
+			a.logger.Error(err)
+			if result == nil {
+				result = err
+			}
+		}
+	}
+	return result
+}
+
+// call runs f within timeout, f ignoring its context is left running
+func (a *app) call(ctx context.Context, timeout time.Duration, f func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	result := make(chan error, 1)
+	go func() {
+		result <- f(ctx)
+	}()
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf(`not done within %s: %v`, timeout, ctx.Err())
+	}
+}
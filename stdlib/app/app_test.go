@@ -0,0 +1,157 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	errors "github.com/mytoko2796/sdk-go/stdlib/error"
+	log "github.com/mytoko2796/sdk-go/stdlib/logger"
+)
+
+// recorder records start and stop of components in call order
+type recorder struct {
+	calls []string
+	fail  map[string]bool
+}
+
+func (r *recorder) component(name string) Component {
+	return Hooks{
+		OnStart: func(ctx context.Context) error {
+			r.calls = append(r.calls, "start "+name)
+			if r.fail[name] {
+				return fmt.Errorf("%s is broken", name)
+			}
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			r.calls = append(r.calls, "stop "+name)
+			return nil
+		},
+	}
+}
+
+func newTestApp() App {
+	return Init(log.Init(log.Options{Output: log.OutputDiscard}), Options{})
+}
+
+func TestOrder(t *testing.T) {
+	type registration struct {
+		name string
+		deps []string
+	}
+	tests := []struct {
+		name    string
+		regs    []registration
+		want    []string
+		wantErr bool
+		code    errors.Code
+	}{
+		{
+			name: "registration order",
+			regs: []registration{{name: "logger"}, {name: "config"}, {name: "health"}},
+			want: []string{"logger", "config", "health"},
+		},
+		{
+			name: "dependencies first, independent stable",
+			regs: []registration{{name: "sql", deps: []string{"config"}}, {name: "cache"}, {name: "config", deps: []string{"logger"}}, {name: "logger"}},
+			want: []string{"logger", "config", "sql", "cache"},
+		},
+		{
+			name:    "unknown dependency",
+			regs:    []registration{{name: "sql", deps: []string{"config"}}},
+			wantErr: true,
+			code:    EcodeUnknownDependency,
+		},
+		{
+			name:    "cycle",
+			regs:    []registration{{name: "a", deps: []string{"b"}}, {name: "b", deps: []string{"c"}}, {name: "c", deps: []string{"a"}}},
+			wantErr: true,
+			code:    EcodeDependencyCycle,
+		},
+		{
+			name:    "self dependency",
+			regs:    []registration{{name: "a", deps: []string{"a"}}},
+			wantErr: true,
+			code:    EcodeDependencyCycle,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp()
+			r := &recorder{}
+			for _, reg := range tt.regs {
+				if err := a.Register(reg.name, r.component(reg.name), reg.deps...); err != nil {
+					t.Fatalf("Register(%s) = %v", reg.name, err)
+				}
+			}
+			got, err := a.Order()
+			if tt.wantErr || err != nil {
+				if !tt.wantErr || errors.ErrCode(err) != tt.code {
+					t.Fatalf("Order() error = %v, want code %d", err, tt.code)
+				}
+				if err := a.Start(context.Background()); err == nil {
+					t.Errorf("Start() = nil, want error")
+				}
+				if len(r.calls) > 0 {
+					t.Errorf("calls = %v, want none", r.calls)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Order() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartRollback(t *testing.T) {
+	a := newTestApp()
+	r := &recorder{fail: map[string]bool{"sql": true}}
+	a.Register("logger", r.component("logger"))
+	a.Register("config", r.component("config"), "logger")
+	a.Register("sql", r.component("sql"), "config")
+	a.Register("server", r.component("server"), "sql")
+
+	if err := a.Start(context.Background()); errors.ErrCode(err) != EcodeStartFailed {
+		t.Fatalf("Start() = %v, want code %d", err, EcodeStartFailed)
+	}
+	want := []string{"start logger", "start config", "start sql", "stop config", "stop logger"}
+	if !reflect.DeepEqual(r.calls, want) {
+		t.Errorf("calls = %v, want %v", r.calls, want)
+	}
+	// nothing is left started to be stopped again
+	r.calls = nil
+	if err := a.Stop(context.Background()); err != nil || len(r.calls) > 0 {
+		t.Errorf("Stop() = %v with calls %v, want no calls", err, r.calls)
+	}
+}
+
+func TestRegisterAfterStart(t *testing.T) {
+	a := newTestApp()
+	registered := make(chan error, 1)
+	a.Register("registering", Hooks{
+		OnStart: func(ctx context.Context) error {
+			// registry is not locked while component starts
+			registered <- a.Register("late", Hooks{})
+			return nil
+		},
+	})
+	if err := a.Start(context.Background()); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	if err := <-registered; err != ErrAlreadyStarted {
+		t.Errorf("Register() within Start = %v, want %v", err, ErrAlreadyStarted)
+	}
+	if err := a.Register("late", Hooks{}); err != ErrAlreadyStarted {
+		t.Errorf("Register() after Start = %v, want %v", err, ErrAlreadyStarted)
+	}
+	if err := a.Start(context.Background()); err != ErrAlreadyStarted {
+		t.Errorf("second Start() = %v, want %v", err, ErrAlreadyStarted)
+	}
+	a.Stop(context.Background())
+	if err := a.Register("late", Hooks{}); err != nil {
+		t.Errorf("Register() after Stop = %v", err)
+	}
+}
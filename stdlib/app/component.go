@@ -0,0 +1,38 @@
+package app
+
+import "context"
+
+// Hooks adapts start and stop functions to Component, nil function does nothing
+type Hooks struct {
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+}
+
+func (h Hooks) Start(ctx context.Context) error {
+	if h.OnStart == nil {
+		return nil
+	}
+	return h.OnStart(ctx)
+}
+
+func (h Hooks) Stop(ctx context.Context) error {
+	if h.OnStop == nil {
+		return nil
+	}
+	return h.OnStop(ctx)
+}
+
+// Stopper is component started by its Init e.g. log.Logger, config.Conf, health.Health or sql.SQL
+type Stopper interface {
+	Stop()
+}
+
+// Stoppable returns component calling Stop of s, start does nothing
+func Stoppable(s Stopper) Component {
+	return Hooks{
+		OnStop: func(ctx context.Context) error {
+			s.Stop()
+			return nil
+		},
+	}
+}
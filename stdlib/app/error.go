@@ -0,0 +1,22 @@
+package app
+
+import (
+	errors "github.com/mytoko2796/sdk-go/stdlib/error"
+)
+
+// Ecode defines package internal error code
+const (
+	// App Error Codes
+	EcodeComponentIsNil = errors.Code(iota)
+	EcodeComponentAlreadyRegistered
+	EcodeUnknownDependency
+	EcodeDependencyCycle
+	EcodeAlreadyStarted
+	EcodeStartFailed
+	EcodeStopFailed
+)
+
+var (
+	// Error App Package
+	ErrAlreadyStarted = errors.NewWithCode(EcodeAlreadyStarted, `components are already started`)
+)
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	errors "github.com/mytoko2796/sdk-go/stdlib/error"
+	"github.com/mytoko2796/sdk-go/stdlib/telemetry/stat"
+	"github.com/mytoko2796/sdk-go/stdlib/telemetry/tag"
+	"go.opencensus.io/stats"
+	tags "go.opencensus.io/tag"
+)
+
+// Status returns error if remote configuration is served from last-known-good cache
+func (c *remoteConf) Status() error {
+	c.cache.mu.RLock()
+	defer c.cache.mu.RUnlock()
+	if c.cache.degraded {
+		return errors.NewWithCode(EcodeDegraded, `remote configuration %s is served from cache %s, age %s`, c.source(), c.opt.RemoteCachePath, time.Since(c.cache.cachedAt).Round(time.Second))
+	}
+	return nil
+}
+
+// Age returns time since last successful fetch, failed fetches after startup make it grow
+func (c *remoteConf) Age() time.Duration {
+	if !c.opt.Enabled {
+		return 0
+	}
+	c.cache.mu.RLock()
+	defer c.cache.mu.RUnlock()
+	return time.Since(c.cache.fetchedAt)
+}
+
+func (c *remoteConf) setFetched(t time.Time) {
+	c.cache.mu.Lock()
+	c.cache.fetchedAt = t
+	c.cache.mu.Unlock()
+}
+
+func (c *remoteConf) setDegraded(degraded bool, cachedAt time.Time) {
+	c.cache.mu.Lock()
+	c.cache.degraded = degraded
+	c.cache.cachedAt = cachedAt
+	c.cache.mu.Unlock()
+}
+
+// readCache loads last-known-good configuration into viper config layer
+func (c *remoteConf) readCache() error {
+	if c.opt.RemoteCachePath == "" {
+		return errors.New(`remote configuration cache is disabled`)
+	}
+	fi, err := os.Stat(c.opt.RemoteCachePath)
+	if err != nil {
+		return err
+	}
+	bs, err := ioutil.ReadFile(c.opt.RemoteCachePath)
+	if err != nil {
+		return err
+	}
+	if err := c.resetConfig(bs); err != nil {
+		return err
+	}
+	c.payload = bs
+	c.setDegraded(true, fi.ModTime())
+	c.setFetched(fi.ModTime())
+	return nil
+}
+
+// writeCache persists raw remote payload without merged or overridden values.
+// File is replaced atomically to never leave a partial cache.
+func (c *remoteConf) writeCache(bs []byte) error {
+	if c.opt.RemoteCachePath == "" {
+		return nil
+	}
+	dir, name := filepath.Split(c.opt.RemoteCachePath)
+	if dir == "" {
+		dir = "."
+	}
+	f, err := ioutil.TempFile(dir, name+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(bs); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Chmod(0600); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), c.opt.RemoteCachePath)
+}
+
+// touchCache sets modification time of cache to now, so its age counts
+// from last successful fetch rather than from last change
+func (c *remoteConf) touchCache() error {
+	if c.opt.RemoteCachePath == "" {
+		return nil
+	}
+	now := time.Now()
+	return os.Chtimes(c.opt.RemoteCachePath, now, now)
+}
+
+// recordCacheAge records age of served cache. 0 means live source is used.
+// Age counts from last successful fetch.
+func (c *remoteConf) recordCacheAge() {
+	var age int64
+	c.cache.mu.RLock()
+	if c.cache.degraded {
+		age = int64(time.Since(c.cache.cachedAt) / time.Second)
+	}
+	c.cache.mu.RUnlock()
+	stats.RecordWithTags(context.Background(),
+		[]tags.Mutator{tags.Upsert(tag.TagConfigSource, c.source())},
+		stat.StatConfigCacheAge.M(age))
+}
@@ -17,6 +17,7 @@ const (
 	infoRemoteConf string = `Remote Configuration:`
 	errConf        string = `%sStatic Configuration Error`
 	errRemoteConf  string = `%sRemote Configuration Error`
+	errSecureConf  string = `%sSecure Configuration Error`
 
 	_OK       string = "[OK]"
 	_FAILED   string = "[FAILED]"
@@ -30,8 +31,9 @@ type cmsType string
 
 
 type Conf interface {
-	// ReadAndWatch Read Configuration and Map configuration to destination.
-	// It watches if configurations changes
+	// ReadAndWatch Read Configuration and Map configuration to destination once,
+	// dest may be nil. It watches if configurations changes, changes are followed
+	// by NewValue or OnChange since dest is never rewritten
 	ReadAndWatch(dest interface{})
 	// Get as config value getter - return interface
 	Get(key string) interface{}
@@ -62,7 +64,7 @@ type Conf interface {
 	Set(key string, v interface{})
 	// IsSet returns true if the key is set or exists
 	IsSet(key string) bool
-	// AllSettings get all settings
+	// AllSettings get all settings, values decrypted from ENC[...] are redacted
 	AllSettings() map[string]interface{}
 	// GetConfigInfo Return File Used in Static Config Only
 	GetConfigInfo() string
@@ -71,12 +73,20 @@ type Conf interface {
 	// Read existing configuration
 	Read(dest interface{}) error
 	// HTTPHandler returns http.HandlerFunc. Useful for configuration info that
-	// is displayed by the service for debugging purpose
-	// Unimplemented for Remote Configuration since Remote Configuration
-	// will be used as secret repository
+	// is displayed by the service for debugging purpose.
+	// Values decrypted from ENC[...] are redacted
 	HTTPHandler() http.HandlerFunc
+	// OnChange registers f to be called after configuration is reloaded.
+	// Errors returned by f are logged
+	OnChange(f func() error)
+	// Status returns error if configuration is served from last-known-good cache
+	// because its live source is unreachable. Always nil for Static Configuration
+	Status() error
+	// Age returns time since configuration was last fetched from its live source, or
+	// since the served cache was written. Always 0 for Static Configuration
+	Age() time.Duration
 	// Stop watching any configuration changes
-	// not implemented for Static Configuration
+	// Static Configuration stops watching mounted directories only
 	Stop()
 }
 
@@ -88,6 +98,14 @@ type Options struct {
 	Host string
 	RestartOnChange bool
 	RemoteWatchPeriod time.Duration
+	// RemoteCachePath local file to persist last-known-good remote configuration.
+	// It is used as fallback if remote provider is unreachable during startup
+	RemoteCachePath string
+	// MountPaths kubernetes ConfigMap/Secret mounted directories where every file is a key.
+	// Mounted values are merged on top of static configuration file
+	MountPaths []string
+	// Secure decrypts ENC[...] values at load time
+	Secure SecureOptions
 }
 
 func Init(logger log.Logger, cms cmsType, opt Options) Conf {
@@ -9,5 +9,7 @@ const (
 	EcodeTimeout
 	EcodeInvalidDest
 	EcodeInvalidSource
+	EcodeDecryptFailed
+	EcodeDegraded
 )
 
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	errors "github.com/mytoko2796/sdk-go/stdlib/error"
+)
+
+const (
+	infoMount string = `Mounted Configuration:`
+
+	// watchDebounce waits for editors and kubernetes to finish replacing files e.g. swapping ..data symlink
+	watchDebounce = 100 * time.Millisecond
+)
+
+// readMount reads kubernetes ConfigMap/Secret mounted volume where every file is a key.
+// Dots in file names create nested keys (e.g. db.password). Hidden entries such as
+// the ..data symlink and timestamped directories are skipped, keys are symlinks into them.
+func readMount(dir string) (map[string]interface{}, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]interface{})
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		// stat follows symlinks
+		fi, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if fi.IsDir() {
+			continue
+		}
+		bs, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		setNested(out, strings.Split(strings.ToLower(name), "."), strings.TrimRight(string(bs), "\r\n"))
+	}
+	return out, nil
+}
+
+func setNested(m map[string]interface{}, path []string, val interface{}) {
+	for _, k := range path[:len(path)-1] {
+		next, ok := m[k].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			m[k] = next
+		}
+		m = next
+	}
+	m[path[len(path)-1]] = val
+}
+
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if nested, ok := v.(map[string]interface{}); ok {
+			v = copyMap(nested)
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// readMounts reads all mounted directories, later mounts take precedence
+func (c *staticConf) readMounts() ([]map[string]interface{}, error) {
+	var mounts []map[string]interface{}
+	for _, dir := range c.opt.MountPaths {
+		m, err := readMount(dir)
+		if err != nil {
+			return nil, errors.WrapWithCode(err, EcodeInvalidSource, `cannot read mounted configuration %s`, dir)
+		}
+		mounts = append(mounts, m)
+	}
+	return mounts, nil
+}
+
+// applyMounts merges mounted values on top of configuration file. Copies are merged
+// since viper keeps nested maps by reference and c.mounts must stay untouched.
+func (c *staticConf) applyMounts(mounts []map[string]interface{}) error {
+	for _, m := range mounts {
+		if err := c.v.MergeConfigMap(copyMap(m)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"github.com/cenkalti/backoff"
 	"github.com/spf13/viper"
+	"io/ioutil"
 	"net/http"
+	"os"
 	"regexp"
-	"syscall"
+	"sync"
 	"time"
 
 	errors "github.com/mytoko2796/sdk-go/stdlib/error"
@@ -22,34 +24,74 @@ const (
 
 // remoteConf holds the viper object to read remote config
 type remoteConf struct {
-	logger  log.Logger
-	v       *viper.Viper
-	opt     Options
-	termSig chan struct{}
+	logger    log.Logger
+	v         *viper.Viper
+	opt       Options
+	provider  remoteProvider
+	decryptor Decryptor
+	secrets   *secretKeys
+	termSig   chan struct{}
+	// merged keeps configs supplied to Merge, they are replayed whenever config layer is reset
+	merged []map[string]interface{}
+	// payload is last raw remote payload, either fetched or read from cache
+	payload []byte
+	cache   *cacheStatus
+	hooks   *hooks
 }
 
+// cacheStatus tells whether remote config is served from last-known-good cache
+type cacheStatus struct {
+	mu       *sync.RWMutex
+	degraded bool
+	cachedAt time.Time
+	// fetchedAt is time of last successful fetch, cache time while degraded since startup
+	fetchedAt time.Time
+}
+
+// remoteProvider implements viper.RemoteProvider
+type remoteProvider struct {
+	provider string
+	endpoint string
+	path     string
+}
+
+func (p remoteProvider) Provider() string      { return p.provider }
+func (p remoteProvider) Endpoint() string      { return p.endpoint }
+func (p remoteProvider) Path() string          { return p.path }
+func (p remoteProvider) SecretKeyring() string { return "" }
+
 // initRemoteConf initialize remote config with logger and supplied options
 func initRemoteConf(logger log.Logger, opt Options) *remoteConf {
 	remote_vp := viper.New()
+	var decryptor Decryptor
 	if opt.Enabled {
-		// if opt.GPGKeyRing != "" {
-		// 	vp.AddSecureRemoteProvider(opt.Provider, opt.Host, opt.Path, opt.GPGKeyRing)
-		// } else {
-		remote_vp.AddRemoteProvider(opt.Provider, opt.Host, opt.Path)
 		rePath, _ := regexp.Compile("/+")
 		// if err != nil {
 		// 	logger.Fatal(FAILED, errRemoteConf, `wrong pattern of path remote config url `, opt.Host)
 		// }
 		opt.Path = rePath.ReplaceAllLiteralString(opt.Path, "/")
 		remote_vp.SetConfigType(opt.Type)
-		// }
+
+		var err error
+		decryptor, err = initDecryptor(opt.Secure)
+		if err != nil {
+			err = errors.WrapWithCode(err, EcodeBadInput, errSecureConf, _FAILED)
+			logger.Fatal(err)
+		}
 		logger.Info(_OK, infoRemoteConf, fmt.Sprintf("%s with key [%s]", opt.Host, opt.Path), ` - Restart on Change: `, opt.RestartOnChange)
 	}
 	return &remoteConf{
-		logger:  logger,
-		v:       remote_vp,
-		opt:     opt,
-		termSig: make(chan struct{}, 1),
+		logger:    logger,
+		v:         remote_vp,
+		opt:       opt,
+		provider:  remoteProvider{provider: opt.Provider, endpoint: opt.Host, path: opt.Path},
+		decryptor: decryptor,
+		secrets:   newSecretKeys(),
+		termSig:   make(chan struct{}, 1),
+		cache: &cacheStatus{
+			mu: &sync.RWMutex{},
+		},
+		hooks: newHooks(),
 	}
 }
 
@@ -60,7 +102,7 @@ func (c *remoteConf) GetConfigInfo() string {
 	return ""
 }
 
-// ReadAndWatch Read Configuration and Map configuration to destination.
+// ReadAndWatch Read Configuration and Map configuration to destination if not nil.
 // It watches if configurations changes
 // It implements RetryBackoff
 func (c *remoteConf) ReadAndWatch(dest interface{}) {
@@ -72,8 +114,12 @@ func (c *remoteConf) ReadAndWatch(dest interface{}) {
 		bo.RandomizationFactor = 0.5
 
 		//read config and store to remote_vp object
+		var payload []byte
 		err := backoff.RetryNotify(
-			c.v.ReadRemoteConfig,
+			func() (err error) {
+				payload, err = c.fetch(false)
+				return err
+			},
 			bo,
 			backoff.Notify(func(err error, duration time.Duration) {
 				if err != nil {
@@ -83,13 +129,28 @@ func (c *remoteConf) ReadAndWatch(dest interface{}) {
 			}))
 		if err != nil {
 			err = errors.WrapWithCode(err, EcodeTimeout, errRemoteConf, _FAILED)
+			// fallback to last-known-good configuration if any
+			if cacheErr := c.readCache(); cacheErr != nil {
+				c.logger.Error(errors.WrapWithCode(cacheErr, EcodeInvalidSource, errRemoteConf, _FAILED))
+				c.logger.Fatal(err)
+			}
+			c.logger.Error(err, ` serving last-known-good configuration from `, c.opt.RemoteCachePath)
+			err = c.decrypt()
+		} else {
+			err = c.refresh(payload)
+		}
+		if err != nil {
+			err = errors.WrapWithCode(err, EcodeDecryptFailed, errSecureConf, _FAILED)
 			c.logger.Fatal(err)
 		}
+		c.recordCacheAge()
 
-		//then marshall
-		if err := c.v.Unmarshal(dest); err != nil {
-			err = errors.WrapWithCode(err, EcodeInvalidDest, errRemoteConf, _FAILED)
-			c.logger.Fatal(err)
+		//then marshall once, changes are followed by NewValue or OnChange
+		if dest != nil {
+			if err := c.v.Unmarshal(dest); err != nil {
+				err = errors.WrapWithCode(err, EcodeInvalidDest, errRemoteConf, _FAILED)
+				c.logger.Fatal(err)
+			}
 		}
 		//set retrieved value as oldConfig. This temp var will be used to find any config changes
 		var oldConfig []byte
@@ -106,18 +167,24 @@ func (c *remoteConf) ReadAndWatch(dest interface{}) {
 				select {
 				case <-ticker.C:
 					// currently, only tested with etcd support
-					err := c.v.WatchRemoteConfig()
+					payload, err := c.fetch(true)
 					if err != nil {
 						err = errors.WrapWithCode(err, EcodeTimeout, errRemoteConf, _FAILED)
 						c.logger.Error(err)
+						c.recordCacheAge()
 						continue
 					}
 
-					// unmarshal new config into our runtime config struct. you can also use channel
-					// to implement a signal to notify the system of the changes
-					if err := c.v.Unmarshal(dest); err != nil {
-						err = errors.WrapWithCode(err, EcodeInvalidDest, errRemoteConf, _FAILED)
+					// live source takes over from last-known-good cache
+					if c.Status() != nil {
+						c.logger.Info(_OK, infoRemoteConf, `live source is restored `, c.source())
+					}
+					err = c.refresh(payload)
+					c.recordCacheAge()
+					if err != nil {
+						err = errors.WrapWithCode(err, EcodeDecryptFailed, errSecureConf, _FAILED)
 						c.logger.Error(err)
+						continue
 					}
 
 					newConfig, err := c.parseConfig()
@@ -127,7 +194,8 @@ func (c *remoteConf) ReadAndWatch(dest interface{}) {
 					}
 
 					if !bytes.Equal(oldConfig, newConfig) {
-						notifyRemoteConfigChange(c.logger, c.opt.RestartOnChange, fmt.Sprintf("%s@%s", c.opt.Path, c.opt.Host))
+						c.hooks.run(c.logger, errRemoteConf)
+						notifyRemoteConfigChange(c.logger, c.opt.RestartOnChange, c.source())
 					}
 					oldConfig = newConfig
 
@@ -211,16 +279,22 @@ func (c *remoteConf) Set(key string, value interface{}) {
 	c.v.Set(key, value)
 }
 
+// OnChange registers f to be called after remote configuration changes
+func (c *remoteConf) OnChange(f func() error) {
+	c.hooks.add(f)
+}
+
 // Merge merges existing configuration with new supplied config
 func (c *remoteConf) Merge(cfg map[string]interface{}) error {
+	c.merged = append(c.merged, cfg)
 	return c.v.MergeConfigMap(cfg)
 }
 
-// HTTPHandler
-// Not implemented due to remote configuration is for managing secret
+// HTTPHandler dumps remote configuration, decrypted values are redacted
+// since remote configuration is used for managing secret
 func (c *remoteConf) HTTPHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		bs, err := yaml.Marshal(c.v.AllSettings())
+		bs, err := yaml.Marshal(c.AllSettings())
 		if err != nil {
 			err = errors.WrapWithCode(err, EcodeInvalidSource, errConf, _FAILED)
 			c.logger.ErrorWithContext(r.Context(), err)
@@ -235,9 +309,80 @@ func (c *remoteConf) Read(dest interface{}) error {
 	return c.v.Unmarshal(dest)
 }
 
-// AllSettings get all settings
+// AllSettings get all settings, decrypted values are redacted
 func (c *remoteConf) AllSettings() map[string]interface{} {
-	return c.v.AllSettings()
+	return c.secrets.redact(c.v.AllSettings())
+}
+
+// fetch reads raw remote payload, watch waits for it through provider watch
+func (c *remoteConf) fetch(watch bool) ([]byte, error) {
+	if viper.RemoteConfig == nil {
+		return nil, errors.New(`remote config requires blank import of github.com/spf13/viper/remote`)
+	}
+	get := viper.RemoteConfig.Get
+	if watch {
+		get = viper.RemoteConfig.Watch
+	}
+	r, err := get(c.provider)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(r)
+}
+
+// refresh rebuilds runtime configuration from payload fetched from live source.
+// Changed payload is persisted to local cache as is, so it stays encrypted at rest,
+// unchanged cache is touched to record the fetch.
+func (c *remoteConf) refresh(payload []byte) error {
+	if err := c.resetConfig(payload); err != nil {
+		return err
+	}
+	c.setDegraded(false, time.Time{})
+	c.setFetched(time.Now())
+	var err error
+	if bytes.Equal(c.payload, payload) {
+		err = c.touchCache()
+	}
+	// removed cache is written again
+	if !bytes.Equal(c.payload, payload) || os.IsNotExist(err) {
+		err = c.writeCache(payload)
+		c.payload = payload
+	}
+	if err != nil {
+		err = errors.WrapWithCode(err, EcodeInvalidSource, errRemoteConf, _FAILED)
+		c.logger.Error(err)
+	}
+	return c.decrypt()
+}
+
+// decrypt decrypts ENC[...] values and records their keys to be redacted
+func (c *remoteConf) decrypt() error {
+	keys, err := decryptConfig(c.v, c.decryptor)
+	if err != nil {
+		return err
+	}
+	c.secrets.set(keys)
+	return nil
+}
+
+// resetConfig replaces viper config layer with src in configured format and
+// replays merged configs. Remote payload is kept in config layer rather than
+// viper key/value store, so previously decrypted values are dropped with it.
+func (c *remoteConf) resetConfig(src []byte) error {
+	if err := c.v.ReadConfig(bytes.NewReader(src)); err != nil {
+		return err
+	}
+	for _, cfg := range c.merged {
+		if err := c.v.MergeConfigMap(cfg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// source returns remote config identifier
+func (c *remoteConf) source() string {
+	return fmt.Sprintf("%s@%s", c.opt.Path, c.opt.Host)
 }
 
 // parseConfig is used for parseConfig
@@ -269,10 +414,7 @@ func (c *remoteConf) Stop() {
 func notifyRemoteConfigChange(logger log.Logger, restartOnChange bool, name string) {
 	logger.Info(_MODIFIED, infoRemoteConf, name)
 	if restartOnChange {
-		once.Do(func() {
-			logger.Info(_RESTART)
-			syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
-		})
+		restart(logger)
 	}
 }
 
@@ -0,0 +1,490 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+
+	errors "github.com/mytoko2796/sdk-go/stdlib/error"
+)
+
+const (
+	jsonSchemaDraft string = `http://json-schema.org/draft-07/schema#`
+
+	// struct tags read by SchemaOf
+	tagKey         string = `mapstructure`
+	tagDefault     string = `default`
+	tagDescription string = `description`
+	tagRequired    string = `required`
+
+	formatDuration string = `duration`
+	formatDateTime string = `date-time`
+)
+
+// DriftKind defines kind of difference between schema and deployed config
+type DriftKind string
+
+const (
+	DriftUnknownKey   DriftKind = `unknown key`
+	DriftMissingKey   DriftKind = `missing key`
+	DriftTypeMismatch DriftKind = `type mismatch`
+)
+
+// Schema is JSON Schema (draft-07 subset) of configuration destination struct
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+
+	// order keeps struct field order to render template
+	order []string
+}
+
+// Drift describes a single difference between schema and deployed config
+type Drift struct {
+	Key      string
+	Kind     DriftKind
+	Expected string
+	Actual   string
+}
+
+func (d Drift) String() string {
+	switch d.Kind {
+	case DriftTypeMismatch:
+		return fmt.Sprintf("%s: %s expected %s got %s", d.Key, d.Kind, d.Expected, d.Actual)
+	default:
+		return fmt.Sprintf("%s: %s", d.Key, d.Kind)
+	}
+}
+
+// SchemaOf reflects over configuration destination struct passed to Conf.Read.
+// Keys follow `mapstructure` tags, otherwise field names. Defaults are taken from
+// `default` tags or non-zero values of dest. Fields tagged `required:"true"` are required
+// and `description` tags are used as documentation. Nil dest returns empty schema.
+func SchemaOf(dest interface{}) *Schema {
+	if dest == nil {
+		return &Schema{Schema: jsonSchemaDraft}
+	}
+	v := reflect.ValueOf(dest)
+	s := schemaOf(v.Type(), v, map[reflect.Type]bool{})
+	s.Schema = jsonSchemaDraft
+	return s
+}
+
+// ParseSchema parses JSON Schema exported by Schema.JSON
+func ParseSchema(bs []byte) (*Schema, error) {
+	s := &Schema{}
+	if err := json.Unmarshal(bs, s); err != nil {
+		return nil, errors.WrapWithCode(err, EcodeInvalidSource, `invalid schema`)
+	}
+	return s, nil
+}
+
+// JSON returns indented JSON Schema
+func (s *Schema) JSON() ([]byte, error) {
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// YAMLTemplate returns documented YAML template filled with defaults
+func (s *Schema) YAMLTemplate() []byte {
+	buf := &bytes.Buffer{}
+	s.writeYAML(buf, 0)
+	return buf.Bytes()
+}
+
+// Diff compares settings (e.g. Conf.AllSettings) against schema and returns
+// unknown keys, missing required keys and type mismatches
+func (s *Schema) Diff(settings map[string]interface{}) []Drift {
+	var drifts []Drift
+	s.diffObject("", settings, &drifts)
+	sort.Slice(drifts, func(i, j int) bool {
+		return drifts[i].Key < drifts[j].Key
+	})
+	return drifts
+}
+
+// DiffFile reads deployed config file and compares it against schema.
+// File format is detected from its extension.
+func (s *Schema) DiffFile(path string) ([]Drift, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, errors.WrapWithCode(err, EcodeBadInput, errConf, _FAILED)
+	}
+	return s.Diff(v.AllSettings()), nil
+}
+
+var (
+	typeDuration = reflect.TypeOf(time.Duration(0))
+	typeTime     = reflect.TypeOf(time.Time{})
+)
+
+// schemaOf builds schema of t. v is used to read defaults and may be invalid.
+func schemaOf(t reflect.Type, v reflect.Value, seen map[reflect.Type]bool) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		if v.IsValid() && !v.IsNil() {
+			v = v.Elem()
+		} else {
+			v = reflect.Value{}
+		}
+	}
+
+	switch t {
+	case typeDuration:
+		s := &Schema{Type: `string`, Format: formatDuration}
+		if v.IsValid() && !v.IsZero() {
+			s.Default = time.Duration(v.Int()).String()
+		}
+		return s
+	case typeTime:
+		return &Schema{Type: `string`, Format: formatDateTime}
+	}
+
+	s := &Schema{}
+	switch t.Kind() {
+	case reflect.Bool:
+		s.Type = `boolean`
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s.Type = `integer`
+	case reflect.Float32, reflect.Float64:
+		s.Type = `number`
+	case reflect.String:
+		s.Type = `string`
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			s.Type = `string`
+			break
+		}
+		s.Type = `array`
+		s.Items = schemaOf(t.Elem(), reflect.Value{}, seen)
+	case reflect.Map:
+		s.Type = `object`
+		s.AdditionalProperties = schemaOf(t.Elem(), reflect.Value{}, seen)
+	case reflect.Struct:
+		s.Type = `object`
+		if seen[t] {
+			return s
+		}
+		seen[t] = true
+		s.Properties = make(map[string]*Schema)
+		addFields(s, t, v, seen)
+		delete(seen, t)
+		return s
+	case reflect.Interface:
+		// any type is accepted
+		return s
+	}
+
+	if v.IsValid() && !v.IsZero() && s.Type != `object` {
+		s.Default = v.Interface()
+	}
+	return s
+}
+
+// addFields adds exported fields of struct t as properties of s
+func addFields(s *Schema, t reflect.Type, v reflect.Value, seen map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		var fv reflect.Value
+		if v.IsValid() {
+			fv = v.Field(i)
+		}
+
+		name, opts := parseTag(f.Tag.Get(tagKey))
+		if name == "-" {
+			continue
+		}
+		if opts[`squash`] {
+			ft, fvv := f.Type, fv
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+				if fvv.IsValid() && !fvv.IsNil() {
+					fvv = fvv.Elem()
+				} else {
+					fvv = reflect.Value{}
+				}
+			}
+			if ft.Kind() == reflect.Struct {
+				addFields(s, ft, fvv, seen)
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		prop := schemaOf(f.Type, fv, seen)
+		prop.Description = f.Tag.Get(tagDescription)
+		if def, ok := f.Tag.Lookup(tagDefault); ok {
+			prop.Default = parseDefault(prop.Type, def)
+		}
+		if req, _ := strconv.ParseBool(f.Tag.Get(tagRequired)); req {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = prop
+		s.order = append(s.order, name)
+	}
+}
+
+// parseTag splits mapstructure tag into name and options
+func parseTag(tag string) (string, map[string]bool) {
+	parts := strings.Split(tag, ",")
+	opts := make(map[string]bool)
+	for _, o := range parts[1:] {
+		opts[strings.TrimSpace(o)] = true
+	}
+	return strings.TrimSpace(parts[0]), opts
+}
+
+// parseDefault converts default tag to respective schema type
+func parseDefault(typ string, def string) interface{} {
+	switch typ {
+	case `boolean`:
+		if b, err := strconv.ParseBool(def); err == nil {
+			return b
+		}
+	case `integer`:
+		if i, err := strconv.ParseInt(def, 10, 64); err == nil {
+			return i
+		}
+	case `number`:
+		if f, err := strconv.ParseFloat(def, 64); err == nil {
+			return f
+		}
+	case `array`, `object`:
+		var val interface{}
+		if err := json.Unmarshal([]byte(def), &val); err == nil {
+			return val
+		}
+	}
+	return def
+}
+
+// keys returns property names in struct order or sorted if schema is parsed
+func (s *Schema) keys() []string {
+	if len(s.order) == len(s.Properties) {
+		return s.order
+	}
+	keys := make([]string, 0, len(s.Properties))
+	for k := range s.Properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// property returns property schema by case insensitive key as viper lowercases keys
+func (s *Schema) property(key string) (string, *Schema) {
+	for name, p := range s.Properties {
+		if strings.EqualFold(name, key) {
+			return name, p
+		}
+	}
+	return "", nil
+}
+
+func (s *Schema) writeYAML(buf *bytes.Buffer, depth int) {
+	indent := strings.Repeat("  ", depth)
+	required := make(map[string]bool)
+	for _, r := range s.Required {
+		required[r] = true
+	}
+	for _, k := range s.keys() {
+		p := s.Properties[k]
+		doc := p.Type
+		if p.Format != "" {
+			doc = fmt.Sprintf("%s (%s)", doc, p.Format)
+		}
+		if required[k] {
+			doc += ", required"
+		}
+		if p.Description != "" {
+			doc = fmt.Sprintf("%s - %s", p.Description, doc)
+		}
+		fmt.Fprintf(buf, "%s# %s\n", indent, doc)
+		if p.Type == `object` && len(p.Properties) > 0 {
+			fmt.Fprintf(buf, "%s%s:\n", indent, k)
+			p.writeYAML(buf, depth+1)
+			continue
+		}
+		fmt.Fprintf(buf, "%s%s: %s\n", indent, k, p.placeholder())
+	}
+}
+
+// placeholder returns default value or zero value rendered as YAML flow
+func (s *Schema) placeholder() string {
+	if s.Default != nil {
+		if bs, err := json.Marshal(s.Default); err == nil {
+			return string(bs)
+		}
+	}
+	switch s.Type {
+	case `string`:
+		return `""`
+	case `integer`, `number`:
+		return `0`
+	case `boolean`:
+		return `false`
+	case `array`:
+		return `[]`
+	case `object`:
+		return `{}`
+	}
+	return `null`
+}
+
+func (s *Schema) diffObject(prefix string, settings map[string]interface{}, drifts *[]Drift) {
+	for k, val := range settings {
+		key := joinKey(prefix, k)
+		_, p := s.property(k)
+		if p == nil {
+			p = s.AdditionalProperties
+		}
+		if p == nil {
+			*drifts = append(*drifts, Drift{Key: key, Kind: DriftUnknownKey})
+			continue
+		}
+		p.diffValue(key, val, drifts)
+	}
+	for name, p := range s.Properties {
+		if _, ok := lookupKey(settings, name); !ok {
+			p.diffValue(joinKey(prefix, name), nil, drifts)
+		}
+	}
+	for _, r := range s.Required {
+		if _, ok := lookupKey(settings, r); !ok {
+			*drifts = append(*drifts, Drift{Key: joinKey(prefix, r), Kind: DriftMissingKey})
+		}
+	}
+}
+
+// diffValue compares val against s, nil val of object reports its missing required keys
+func (s *Schema) diffValue(key string, val interface{}, drifts *[]Drift) {
+	if val == nil && s.Type == `object` && s.Properties != nil {
+		s.diffObject(key, nil, drifts)
+		return
+	}
+	if val == nil || s.Type == "" {
+		return
+	}
+	if !s.accepts(val) {
+		*drifts = append(*drifts, Drift{Key: key, Kind: DriftTypeMismatch, Expected: s.typeName(), Actual: fmt.Sprintf("%T", val)})
+		return
+	}
+	switch s.Type {
+	case `object`:
+		if m, ok := toStringMap(val); ok && (s.Properties != nil || s.AdditionalProperties != nil) {
+			s.diffObject(key, m, drifts)
+		}
+	case `array`:
+		if s.Items == nil {
+			return
+		}
+		if l, ok := val.([]interface{}); ok {
+			for i, item := range l {
+				s.Items.diffValue(fmt.Sprintf("%s[%d]", key, i), item, drifts)
+			}
+		}
+	}
+}
+
+// accepts checks whether val decoded from config file matches schema type
+func (s *Schema) accepts(val interface{}) bool {
+	switch s.Type {
+	case `boolean`:
+		_, ok := val.(bool)
+		return ok
+	case `integer`:
+		switch n := val.(type) {
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			return true
+		case float64:
+			return n == math.Trunc(n)
+		}
+		return false
+	case `number`:
+		switch val.(type) {
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+			return true
+		}
+		return false
+	case `string`:
+		str, ok := val.(string)
+		if s.Format == formatDuration {
+			if !ok {
+				// viper decodes plain numbers as nanoseconds
+				return (&Schema{Type: `integer`}).accepts(val)
+			}
+			_, err := time.ParseDuration(str)
+			return err == nil
+		}
+		if s.Format == formatDateTime {
+			_, isTime := val.(time.Time)
+			return ok || isTime
+		}
+		return ok
+	case `array`:
+		_, ok := val.([]interface{})
+		return ok
+	case `object`:
+		_, ok := toStringMap(val)
+		return ok
+	}
+	return true
+}
+
+func (s *Schema) typeName() string {
+	if s.Format != "" {
+		return fmt.Sprintf("%s(%s)", s.Type, s.Format)
+	}
+	return s.Type
+}
+
+func toStringMap(val interface{}) (map[string]interface{}, bool) {
+	switch m := val.(type) {
+	case map[string]interface{}:
+		return m, true
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			out[fmt.Sprint(k)] = v
+		}
+		return out, true
+	}
+	return nil, false
+}
+
+func lookupKey(settings map[string]interface{}, key string) (interface{}, bool) {
+	for k, v := range settings {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
@@ -0,0 +1,323 @@
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"filippo.io/age"
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/openpgp"
+
+	errors "github.com/mytoko2796/sdk-go/stdlib/error"
+)
+
+const (
+	// DecryptorAESGCM decrypts values sealed with AES-GCM using a symmetric key file
+	DecryptorAESGCM string = `aesgcm`
+	// DecryptorAge decrypts values encrypted by age using an identity file
+	DecryptorAge string = `age`
+	// DecryptorGPG decrypts values encrypted by gpg using a secret keyring
+	DecryptorGPG string = `gpg`
+
+	// redacted replaces decrypted values in dumped settings
+	redacted string = `[REDACTED]`
+)
+
+var (
+	// encValue matches encrypted config value e.g. ENC[base64 ciphertext]
+	encValue = regexp.MustCompile(`^ENC\[([A-Za-z0-9+/=\s]+)\]$`)
+)
+
+// Decryptor decrypts encrypted configuration values.
+// Ciphertext is the base64 decoded content of ENC[...] blocks.
+type Decryptor interface {
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// SecureOptions defines how encrypted configuration values are decrypted
+type SecureOptions struct {
+	Enabled bool
+	// Mode defines built-in decryptor: aesgcm, age or gpg
+	Mode string
+	// KeyFile path of AES-GCM key file (raw, hex or base64 encoded 16/24/32 bytes key)
+	// or age identity file
+	KeyFile string
+	// KeyRing path of gpg secret keyring (binary or armored)
+	KeyRing string
+	// Passphrase unlocks gpg private keys if they are protected
+	Passphrase string
+	// Decryptor custom decryptor. It takes precedence over Mode
+	Decryptor Decryptor
+}
+
+// initDecryptor returns Decryptor based on supplied secure options.
+// It returns nil if secure configuration is disabled.
+func initDecryptor(opt SecureOptions) (Decryptor, error) {
+	if !opt.Enabled {
+		return nil, nil
+	}
+	if opt.Decryptor != nil {
+		return opt.Decryptor, nil
+	}
+	switch opt.Mode {
+	case DecryptorAESGCM:
+		return NewAESGCMDecryptor(opt.KeyFile)
+	case DecryptorAge:
+		return NewAgeDecryptor(opt.KeyFile)
+	case DecryptorGPG:
+		return NewGPGDecryptor(opt.KeyRing, opt.Passphrase)
+	default:
+		return nil, errors.NewWithCode(EcodeBadInput, `unsupported decryptor %s`, opt.Mode)
+	}
+}
+
+// aesgcmDecryptor decrypts nonce|ciphertext sealed by AES-GCM
+type aesgcmDecryptor struct {
+	aead cipher.AEAD
+}
+
+// NewAESGCMDecryptor returns Decryptor using symmetric key stored in keyFile.
+// Ciphertext is expected to be prefixed by its nonce.
+func NewAESGCMDecryptor(keyFile string) (Decryptor, error) {
+	raw, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return nil, errors.WrapWithCode(err, EcodeBadInput, `cannot read key file %s`, keyFile)
+	}
+	key, err := parseKey(raw)
+	if err != nil {
+		return nil, errors.WrapWithCode(err, EcodeBadInput, `invalid key file %s`, keyFile)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.WrapWithCode(err, EcodeBadInput, `invalid key file %s`, keyFile)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.WrapWithCode(err, EcodeBadInput, `invalid key file %s`, keyFile)
+	}
+	return &aesgcmDecryptor{aead: aead}, nil
+}
+
+func (d *aesgcmDecryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	size := d.aead.NonceSize()
+	if len(ciphertext) < size {
+		return nil, errors.New(`ciphertext is too short`)
+	}
+	return d.aead.Open(nil, ciphertext[:size], ciphertext[size:], nil)
+}
+
+// parseKey accepts raw, hex or base64 encoded AES key
+func parseKey(raw []byte) ([]byte, error) {
+	if isAESKeySize(len(raw)) {
+		return raw, nil
+	}
+	s := strings.TrimSpace(string(raw))
+	if key, err := hex.DecodeString(s); err == nil && isAESKeySize(len(key)) {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(s); err == nil && isAESKeySize(len(key)) {
+		return key, nil
+	}
+	return nil, errors.New(`key must be 16, 24 or 32 bytes`)
+}
+
+func isAESKeySize(n int) bool {
+	return n == 16 || n == 24 || n == 32
+}
+
+// ageDecryptor decrypts age encrypted payload
+type ageDecryptor struct {
+	identities []age.Identity
+}
+
+// NewAgeDecryptor returns Decryptor using age identities stored in identityFile
+func NewAgeDecryptor(identityFile string) (Decryptor, error) {
+	f, err := os.Open(identityFile)
+	if err != nil {
+		return nil, errors.WrapWithCode(err, EcodeBadInput, `cannot read identity file %s`, identityFile)
+	}
+	defer f.Close()
+	ids, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, errors.WrapWithCode(err, EcodeBadInput, `invalid identity file %s`, identityFile)
+	}
+	return &ageDecryptor{identities: ids}, nil
+}
+
+func (d *ageDecryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	r, err := age.Decrypt(bytes.NewReader(ciphertext), d.identities...)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(r)
+}
+
+// gpgDecryptor decrypts gpg encrypted payload
+type gpgDecryptor struct {
+	keyring openpgp.EntityList
+}
+
+// NewGPGDecryptor returns Decryptor using gpg secret keyring. Passphrase is used to
+// unlock protected private keys.
+func NewGPGDecryptor(keyRing string, passphrase string) (Decryptor, error) {
+	raw, err := ioutil.ReadFile(keyRing)
+	if err != nil {
+		return nil, errors.WrapWithCode(err, EcodeBadInput, `cannot read keyring %s`, keyRing)
+	}
+	keyring, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(raw))
+	if err != nil {
+		keyring, err = openpgp.ReadKeyRing(bytes.NewReader(raw))
+		if err != nil {
+			return nil, errors.WrapWithCode(err, EcodeBadInput, `invalid keyring %s`, keyRing)
+		}
+	}
+	if passphrase != "" {
+		for _, e := range keyring {
+			if e.PrivateKey != nil && e.PrivateKey.Encrypted {
+				if err := e.PrivateKey.Decrypt([]byte(passphrase)); err != nil {
+					return nil, errors.WrapWithCode(err, EcodeBadInput, `cannot unlock keyring %s`, keyRing)
+				}
+			}
+			for _, sub := range e.Subkeys {
+				if sub.PrivateKey != nil && sub.PrivateKey.Encrypted {
+					if err := sub.PrivateKey.Decrypt([]byte(passphrase)); err != nil {
+						return nil, errors.WrapWithCode(err, EcodeBadInput, `cannot unlock keyring %s`, keyRing)
+					}
+				}
+			}
+		}
+	}
+	return &gpgDecryptor{keyring: keyring}, nil
+}
+
+func (d *gpgDecryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	md, err := openpgp.ReadMessage(bytes.NewReader(ciphertext), d.keyring, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(md.UnverifiedBody)
+}
+
+// decryptConfig decrypts all ENC[...] values in viper and merges the plaintext back.
+// It must be called after every (re)read since reading config replaces merged values.
+// It returns keys of decrypted values.
+func decryptConfig(v *viper.Viper, d Decryptor) ([]string, error) {
+	if d == nil {
+		return nil, nil
+	}
+	decrypted, changed, err := decryptValue(d, v.AllSettings(), false)
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return nil, nil
+	}
+	m := decrypted.(map[string]interface{})
+	return leafKeys(m, ""), v.MergeConfigMap(m)
+}
+
+// leafKeys returns dotted keys of non map values
+func leafKeys(m map[string]interface{}, prefix string) []string {
+	var keys []string
+	for k, v := range m {
+		if nested, ok := v.(map[string]interface{}); ok {
+			keys = append(keys, leafKeys(nested, prefix+k+".")...)
+			continue
+		}
+		keys = append(keys, prefix+k)
+	}
+	return keys
+}
+
+// secretKeys holds keys of decrypted values, so plaintext is never dumped
+type secretKeys struct {
+	mu   *sync.RWMutex
+	keys []string
+}
+
+func newSecretKeys() *secretKeys {
+	return &secretKeys{
+		mu: &sync.RWMutex{},
+	}
+}
+
+func (s *secretKeys) set(keys []string) {
+	s.mu.Lock()
+	s.keys = keys
+	s.mu.Unlock()
+}
+
+// redact replaces decrypted values of settings returned by viper AllSettings in place
+func (s *secretKeys) redact(settings map[string]interface{}) map[string]interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, key := range s.keys {
+		path := strings.Split(key, ".")
+		m := settings
+		for _, k := range path[:len(path)-1] {
+			if m, _ = m[k].(map[string]interface{}); m == nil {
+				break
+			}
+		}
+		if _, ok := m[path[len(path)-1]]; ok {
+			m[path[len(path)-1]] = redacted
+		}
+	}
+	return settings
+}
+
+// decryptValue walks maps and slices recursively. Maps returned only contain
+// the decrypted keys unless full is set, slices are always returned whole.
+func decryptValue(d Decryptor, val interface{}, full bool) (interface{}, bool, error) {
+	switch t := val.(type) {
+	case string:
+		m := encValue.FindStringSubmatch(strings.TrimSpace(t))
+		if m == nil {
+			return t, false, nil
+		}
+		ciphertext, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(m[1]), ""))
+		if err != nil {
+			return nil, false, errors.WrapWithCode(err, EcodeInvalidSource, `invalid encrypted value`)
+		}
+		plaintext, err := d.Decrypt(ciphertext)
+		if err != nil {
+			return nil, false, errors.WrapWithCode(err, EcodeDecryptFailed, `cannot decrypt value`)
+		}
+		return string(plaintext), true, nil
+	case map[string]interface{}:
+		out := make(map[string]interface{})
+		var anyChanged bool
+		for k, v := range t {
+			dv, changed, err := decryptValue(d, v, full)
+			if err != nil {
+				return nil, false, errors.Wrap(err, `key %s`, k)
+			}
+			if changed || full {
+				out[k] = dv
+			}
+			anyChanged = anyChanged || changed
+		}
+		return out, anyChanged, nil
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		var anyChanged bool
+		for i, v := range t {
+			dv, changed, err := decryptValue(d, v, true)
+			if err != nil {
+				return nil, false, errors.Wrap(err, `index %d`, i)
+			}
+			out[i] = dv
+			anyChanged = anyChanged || changed
+		}
+		return out, anyChanged, nil
+	}
+	return val, false, nil
+}
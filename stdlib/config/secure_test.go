@@ -0,0 +1,125 @@
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	log "github.com/mytoko2796/sdk-go/stdlib/logger"
+	"github.com/spf13/viper"
+)
+
+const (
+	testPassword = "s3cr3t-password"
+	testToken    = "s3cr3t-token"
+)
+
+// fakeRemote serves payload for every remote provider
+type fakeRemote struct {
+	payload []byte
+}
+
+func (f fakeRemote) Get(rp viper.RemoteProvider) (io.Reader, error) {
+	return bytes.NewReader(f.payload), nil
+}
+
+func (f fakeRemote) Watch(rp viper.RemoteProvider) (io.Reader, error) {
+	return bytes.NewReader(f.payload), nil
+}
+
+func (f fakeRemote) WatchChannel(rp viper.RemoteProvider) (<-chan *viper.RemoteResponse, chan bool) {
+	return nil, nil
+}
+
+// writeEncryptedConfig writes AES-GCM key file and yaml config with encrypted values
+func writeEncryptedConfig(t *testing.T) (keyFile string, payload []byte) {
+	t.Helper()
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	keyFile = filepath.Join(t.TempDir(), "config.key")
+	if err := ioutil.WriteFile(keyFile, key, 0600); err != nil {
+		t.Fatal(err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypt := func(plaintext string) string {
+		nonce := make([]byte, aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			t.Fatal(err)
+		}
+		return "ENC[" + base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, []byte(plaintext), nil)) + "]"
+	}
+	payload = []byte(fmt.Sprintf("db:\n  host: localhost\n  password: %s\ntokens:\n  - %s\n", encrypt(testPassword), encrypt(testToken)))
+	return keyFile, payload
+}
+
+func TestHTTPHandlerRedactsDecryptedValues(t *testing.T) {
+	logger := log.Init(log.Options{Output: log.OutputDiscard})
+	keyFile, payload := writeEncryptedConfig(t)
+	secure := SecureOptions{Enabled: true, Mode: DecryptorAESGCM, KeyFile: keyFile}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := ioutil.WriteFile(path, payload, 0600); err != nil {
+		t.Fatal(err)
+	}
+	viper.RemoteConfig = fakeRemote{payload: payload}
+
+	tests := []struct {
+		name string
+		cms  cmsType
+		opt  Options
+	}{
+		{name: "static", cms: AppStaticConfig, opt: Options{Path: path, Type: "yaml", Secure: secure}},
+		{name: "remote", cms: AppRemoteConfig, opt: Options{Enabled: true, Provider: "consul", Host: "localhost:8500", Path: "app", Type: "yaml", RemoteWatchPeriod: time.Hour, Secure: secure}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := Init(logger, tt.cms, tt.opt)
+			defer conf.Stop()
+			var dest struct {
+				DB struct {
+					Host     string
+					Password string
+				}
+				Tokens []string
+			}
+			conf.ReadAndWatch(&dest)
+			if dest.DB.Password != testPassword || conf.GetString("db.password") != testPassword {
+				t.Fatalf("password = %q, want decrypted value", dest.DB.Password)
+			}
+
+			rec := httptest.NewRecorder()
+			conf.HTTPHandler()(rec, httptest.NewRequest("GET", "/config", nil))
+			body := rec.Body.String()
+			for _, secret := range []string{testPassword, testToken} {
+				if strings.Contains(body, secret) {
+					t.Errorf("HTTPHandler() returned plaintext %q:\n%s", secret, body)
+				}
+			}
+			if !strings.Contains(body, "localhost") || !strings.Contains(body, redacted) {
+				t.Errorf("HTTPHandler() = %s, want plain values kept and decrypted values redacted", body)
+			}
+			if got := conf.AllSettings()["db"].(map[string]interface{})["password"]; got != redacted {
+				t.Errorf("AllSettings() password = %v, want %s", got, redacted)
+			}
+		})
+	}
+}
@@ -1,10 +1,15 @@
 package config
 
 import (
+	"bytes"
 	"github.com/fsnotify/fsnotify"
 	log "github.com/mytoko2796/sdk-go/stdlib/logger"
 	"github.com/spf13/viper"
+	"io/ioutil"
 	"net/http"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -13,15 +18,21 @@ import (
 	yaml "gopkg.in/yaml.v2"
 )
 
-var (
-	once = &sync.Once{}
-)
-
 // staticConf holds the viper object to read static config
 type staticConf struct {
-	logger log.Logger
-	v      *viper.Viper
-	opt    Options
+	// mu guards v, so readers never see configuration in the middle of reload
+	mu        *sync.RWMutex
+	logger    log.Logger
+	v         *viper.Viper
+	opt       Options
+	decryptor Decryptor
+	secrets   *secretKeys
+	hooks     *hooks
+	// file and mounts hold last read content of configuration file and mounted directories
+	file    []byte
+	mounts  []map[string]interface{}
+	termSig chan struct{}
+	endOnce *sync.Once
 }
 
 func initStaticConf(logger log.Logger, opt Options) *staticConf {
@@ -29,119 +40,290 @@ func initStaticConf(logger log.Logger, opt Options) *staticConf {
 	static_vp.SetConfigFile(opt.Path)
 	static_vp.SetConfigType(opt.Type)
 
+	decryptor, err := initDecryptor(opt.Secure)
+	if err != nil {
+		err = errors.WrapWithCode(err, EcodeBadInput, errSecureConf, _FAILED)
+		logger.Fatal(err)
+	}
+
 	return &staticConf{
-		logger: logger,
-		v:      static_vp,
-		opt:    opt,
+		mu:        &sync.RWMutex{},
+		logger:    logger,
+		v:         static_vp,
+		opt:       opt,
+		decryptor: decryptor,
+		secrets:   newSecretKeys(),
+		hooks:     newHooks(),
+		termSig:   make(chan struct{}),
+		endOnce:   &sync.Once{},
 	}
 }
 
-// ReadAndWatch Read Configuration and Map configuration to destination.
-// It watches if configurations changes
+// ReadAndWatch Read Configuration and Map configuration to destination if not nil.
+// It watches if configurations changes including mounted directories
 func (c *staticConf) ReadAndWatch(dest interface{}) {
 	if c.opt.Enabled {
-		if err := c.v.ReadInConfig(); err != nil {
-			err := errors.WrapWithCode(err, EcodeBadInput, errConf, _FAILED)
+		c.mu.Lock()
+		_, err := c.read()
+		c.mu.Unlock()
+		if err != nil {
 			c.logger.Fatal(err)
 		}
-		if err := c.v.Unmarshal(dest); err != nil {
-			err := errors.WrapWithCode(err, EcodeInvalidDest, errConf, _FAILED)
+		if dest != nil {
+			if err := c.Read(dest); err != nil {
+				err := errors.WrapWithCode(err, EcodeInvalidDest, errConf, _FAILED)
+				c.logger.Fatal(err)
+			}
+		}
+		if err := c.watch(); err != nil {
+			err = errors.WrapWithCode(err, EcodeInvalidSource, errConf, _FAILED)
 			c.logger.Fatal(err)
 		}
-		c.v.OnConfigChange(func(e fsnotify.Event) {
-			notifyStaticConfigChange(c.logger, c.opt.RestartOnChange, e.Name)
-		})
-		c.v.WatchConfig()
 		c.logger.Info(_OK, infoConf, c.v.ConfigFileUsed(), ` - Restart on Change: `, c.opt.RestartOnChange)
 	}
 }
 
+// read reads configuration file and mounted directories, merges mounted values on top
+// of the file and decrypts the result. It returns changed source, empty if neither
+// changed since last read. Last read configuration is restored on failure.
+// Caller must hold c.mu
+func (c *staticConf) read() (string, error) {
+	bs, err := ioutil.ReadFile(c.opt.Path)
+	if err != nil {
+		return "", errors.WrapWithCode(err, EcodeBadInput, errConf, _FAILED)
+	}
+	mounts, err := c.readMounts()
+	if err != nil {
+		return "", errors.WrapWithCode(err, EcodeInvalidSource, errConf, _FAILED)
+	}
+	source := c.opt.Path
+	if bytes.Equal(bs, c.file) {
+		if reflect.DeepEqual(mounts, c.mounts) {
+			return "", nil
+		}
+		source = strings.Join(c.opt.MountPaths, ",")
+	}
+	if err := c.apply(bs, mounts); err != nil {
+		if c.file != nil {
+			c.apply(c.file, c.mounts)
+		}
+		return "", err
+	}
+	c.file = bs
+	c.mounts = mounts
+	return source, nil
+}
+
+// apply replaces viper config layer with file content, merges mounted values and decrypts them.
+// Caller must hold c.mu
+func (c *staticConf) apply(file []byte, mounts []map[string]interface{}) error {
+	if err := c.v.ReadConfig(bytes.NewReader(file)); err != nil {
+		return errors.WrapWithCode(err, EcodeBadInput, errConf, _FAILED)
+	}
+	if err := c.applyMounts(mounts); err != nil {
+		return errors.WrapWithCode(err, EcodeInvalidSource, errConf, _FAILED)
+	}
+	if err := c.decrypt(); err != nil {
+		return errors.WrapWithCode(err, EcodeDecryptFailed, errSecureConf, _FAILED)
+	}
+	return nil
+}
+
+// watch watches directories of configuration file and mounted directories. Editors and
+// kubernetes replace files rather than write them, e.g. by swapping ..data symlink, so
+// directories are watched and configuration is compared after every event.
+func (c *staticConf) watch() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	for _, dir := range append([]string{filepath.Dir(c.opt.Path)}, c.opt.MountPaths...) {
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return err
+		}
+	}
+	for _, dir := range c.opt.MountPaths {
+		c.logger.Info(_OK, infoMount, dir)
+	}
+
+	go func() {
+		defer watcher.Close()
+		debounce := time.NewTimer(watchDebounce)
+		debounce.Stop()
+		for {
+			select {
+			case _, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				debounce.Reset(watchDebounce)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				err = errors.WrapWithCode(err, EcodeInvalidSource, errConf, _FAILED)
+				c.logger.Error(err)
+			case <-debounce.C:
+				c.reload()
+			case <-c.termSig:
+				debounce.Stop()
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// reload re-reads configuration and publishes it if changed
+func (c *staticConf) reload() {
+	c.mu.Lock()
+	source, err := c.read()
+	c.mu.Unlock()
+	if err != nil {
+		c.logger.Error(err)
+		return
+	}
+	if source != "" {
+		c.publish(source)
+	}
+}
+
+// publish calls change hooks and notifies the change.
+// Caller must not hold c.mu, hooks may read configuration
+func (c *staticConf) publish(name string) {
+	c.hooks.run(c.logger, errConf)
+	notifyStaticConfigChange(c.logger, c.opt.RestartOnChange, name)
+}
+
+// decrypt decrypts ENC[...] values and records their keys to be redacted
+func (c *staticConf) decrypt() error {
+	keys, err := decryptConfig(c.v, c.decryptor)
+	if err != nil {
+		return err
+	}
+	c.secrets.set(keys)
+	return nil
+}
+
 // GetConfigInfo Return File Used in Static Config Only
 func (c *staticConf) GetConfigInfo() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.v.ConfigFileUsed()
 }
 
 // Get as config value getter - return interface
 func (c *staticConf) Get(key string) interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.v.Get(key)
 }
 
 // GetBool as config value getter - returns boolean
 func (c *staticConf) GetBool(key string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.v.GetBool(key)
 }
 
 // GetFloat64 as config value getter - returns float64
 func (c *staticConf) GetFloat64(key string) float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.v.GetFloat64(key)
 }
 
 // GetInt as config value getter - returns Int
 func (c *staticConf) GetInt(key string) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.v.GetInt(key)
 }
 
 // GetInt32 as config value getter - returns Int32
 func (c *staticConf) GetInt32(key string) int32 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.v.GetInt32(key)
 }
 
 // GetInt64 as config value getter - returns Int64
 func (c *staticConf) GetInt64(key string) int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.v.GetInt64(key)
 }
 
 // GetString as config value getter - returns String
 func (c *staticConf) GetString(key string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.v.GetString(key)
 }
 
 // GetStringMap as config value getter - returns map[string]interface
 func (c *staticConf) GetStringMap(key string) map[string]interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.v.GetStringMap(key)
 }
 
 // GetStringMapString as config value getter - returns map[string]string
 func (c *staticConf) GetStringMapString(key string) map[string]string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.v.GetStringMapString(key)
 }
 
 // GetStringSlice as config value getter - returns []string
 func (c *staticConf) GetStringSlice(key string) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.v.GetStringSlice(key)
 }
 
 // GetTime as config value getter - returns time.Time
 func (c *staticConf) GetTime(key string) time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.v.GetTime(key)
 }
 
 // GetDuration as config value getter - returns time.Duration
 // tested only with "s" or "ms"
 func (c *staticConf) GetDuration(key string) time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.v.GetDuration(key)
 }
 
 // IsSet returns true if the key is set or exists
 func (c *staticConf) IsSet(key string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.v.IsSet(key)
 }
 
 // Set as config value setter
 func (c *staticConf) Set(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.v.Set(key, value)
 }
 
 // Merge merges existing configuration with new supplied config
 func (c *staticConf) Merge(cfg map[string]interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	return c.v.MergeConfigMap(cfg)
 }
 
 // HTTPHandler returns http.HandlerFunc. Useful for configuration info that
-// is displayed by the service for debugging purpose
+// is displayed by the service for debugging purpose, decrypted values are redacted
 func (c *staticConf) HTTPHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		bs, err := yaml.Marshal(c.v.AllSettings())
+		bs, err := yaml.Marshal(c.AllSettings())
 		if err != nil {
 			err = errors.WrapWithCode(err, EcodeInvalidSource, errConf, _FAILED)
 			c.logger.ErrorWithContext(r.Context(), err)
@@ -153,28 +335,51 @@ func (c *staticConf) HTTPHandler() http.HandlerFunc {
 
 // Read existing configuration
 func (c *staticConf) Read(dest interface{}) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.v.Unmarshal(dest)
 }
 
-// AllSettings get all settings
+// AllSettings get all settings, decrypted values are redacted
 func (c *staticConf) AllSettings() map[string]interface{} {
-	return c.v.AllSettings()
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.secrets.redact(c.v.AllSettings())
 }
 
 // notifyStaticConfigChange send sighup signal if any configurations change
 func notifyStaticConfigChange(logger log.Logger, restartOnChange bool, name string) {
 	logger.Info(_MODIFIED, infoConf, name)
 	if restartOnChange {
-		once.Do(func() {
-			logger.Info(_RESTART)
-			syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
-		})
+		restart(logger)
 	}
 }
 
-// Stop watching any configuration changes
-// not implemented
+// restart sends sighup signal on every change, so a failed upgrade is retried on next change.
+// Grace keeps one signal arriving while upgrade is in progress pending and upgrades again afterwards.
+func restart(logger log.Logger) {
+	logger.Info(_RESTART)
+	syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+}
+
+// OnChange registers f to be called after configuration file is reloaded
+func (c *staticConf) OnChange(f func() error) {
+	c.hooks.add(f)
+}
+
+// Status always returns nil since static configuration has no remote source
+func (c *staticConf) Status() error {
+	return nil
+}
+
+// Age always returns 0 since static configuration file is watched
+func (c *staticConf) Age() time.Duration {
+	return 0
+}
+
+// Stop watching configuration file and mounted directories changes
 func (c *staticConf) Stop() {
-	//not implemented
-	return
+	c.endOnce.Do(func() {
+		close(c.termSig)
+	})
 }
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"sync"
+	"sync/atomic"
+
+	errors "github.com/mytoko2796/sdk-go/stdlib/error"
+	log "github.com/mytoko2796/sdk-go/stdlib/logger"
+)
+
+// Validator is implemented by configuration structs that need validation
+// before they are published to readers
+type Validator interface {
+	Validate() error
+}
+
+// Value holds the latest configuration snapshot mapped to T. Snapshot is replaced
+// atomically only after it is fully unmarshalled and validated, so readers never
+// see half-written structs during hot reload. Use it to follow changes watched by
+// ReadAndWatch(nil) whenever configuration is read by other goroutines.
+type Value[T any] struct {
+	conf Conf
+	ptr  atomic.Pointer[T]
+}
+
+// NewValue returns Value bound to conf. Configuration must be read
+// (e.g. ReadAndWatch(nil)) before NewValue is called.
+func NewValue[T any](conf Conf) (*Value[T], error) {
+	v := &Value[T]{conf: conf}
+	if err := v.reload(); err != nil {
+		return nil, err
+	}
+	conf.OnChange(v.reload)
+	return v, nil
+}
+
+// Load returns current configuration snapshot
+func (v *Value[T]) Load() T {
+	return *v.ptr.Load()
+}
+
+// reload unmarshals configuration into a new T and swaps it if valid
+func (v *Value[T]) reload() error {
+	dest := new(T)
+	if err := v.conf.Read(dest); err != nil {
+		return errors.WrapWithCode(err, EcodeInvalidDest, `cannot unmarshal configuration snapshot`)
+	}
+	if err := validate(dest); err != nil {
+		return errors.WrapWithCode(err, EcodeInvalidSource, `invalid configuration snapshot`)
+	}
+	v.ptr.Store(dest)
+	return nil
+}
+
+// validate calls Validate on value or pointer receiver if implemented
+func validate(dest interface{}) error {
+	if vd, ok := dest.(Validator); ok {
+		return vd.Validate()
+	}
+	return nil
+}
+
+// hooks holds functions called after configuration is reloaded
+type hooks struct {
+	mu *sync.RWMutex
+	fs []func() error
+}
+
+func newHooks() *hooks {
+	return &hooks{
+		mu: &sync.RWMutex{},
+	}
+}
+
+func (h *hooks) add(f func() error) {
+	h.mu.Lock()
+	h.fs = append(h.fs, f)
+	h.mu.Unlock()
+}
+
+// run calls all hooks and logs their errors. Hooks are called without the lock
+// held, so a hook may register other hooks e.g. by NewValue.
+func (h *hooks) run(logger log.Logger, errMsg string) {
+	h.mu.RLock()
+	fs := make([]func() error, len(h.fs))
+	copy(fs, h.fs)
+	h.mu.RUnlock()
+	for _, f := range fs {
+		if err := f(); err != nil {
+			logger.Error(errors.Wrap(err, errMsg, _FAILED))
+		}
+	}
+}
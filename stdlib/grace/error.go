@@ -11,5 +11,16 @@ const (
 	EcodeAppShutdownFailed
 	EcodeHTTPServerFailed
 	EcodeTelemetryServerFailed
+	EcodeStartFailed
+	EcodeListenFailed
+	EcodeNotPacketServer
+	EcodeUpgradeInProgress
+	EcodeUnauthorized
+	EcodeInvalidReadyTimeout
+)
+
+var (
+	// Error Grace Package
+	ErrUpgradeInProgress = errors.NewWithCode(EcodeUpgradeInProgress, `upgrade is already in progress`)
 )
 
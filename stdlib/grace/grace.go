@@ -1,11 +1,16 @@
 package grace
 
 import (
+	"context"
 	"fmt"
 	"github.com/cloudflare/tableflip"
+	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
+	application "github.com/mytoko2796/sdk-go/stdlib/app"
+	"github.com/mytoko2796/sdk-go/stdlib/health"
 	log "github.com/mytoko2796/sdk-go/stdlib/logger"
 	"github.com/mytoko2796/sdk-go/stdlib/telemetry"
 	"github.com/mytoko2796/sdk-go/stdlib/httpserver"
@@ -16,8 +21,8 @@ import (
 const (
 	infoGrace string = `Grace Upgrader:`
 	errGrace  string = `%s Grace Upgrader Error`
-	infoTCP   string = `TCP Listen`
-	errTCP    string = `%s TCP Listen Error`
+	infoListen string = `Listen`
+	errListen  string = `%s Listen %s Error`
 
 	_UPGRADE string = `[UPGRADED]`
 	_OK      string = `[OK]`
@@ -30,52 +35,117 @@ type App interface {
 	Serve()
 	// Stop stopping app
 	Stop()
+	// OnShutdown registers hook run after servers are drained as component of app registry,
+	// it must be called before Serve. Hooks run in reverse order of dependencies named in deps
+	// and otherwise of registration, so dependencies are registered first e.g. config then sql.
+	// Stop of e.g. sql.SQL is registered by app.Stoppable(db).Stop.
+	OnShutdown(name string, hook ShutdownFunc, deps ...string)
+	// Register adds server listening on addresses inherited across upgrades, it must be called before Serve
+	Register(s Server)
+	// Upgrade starts new process and returns once it is ready, it is also triggered by SIGHUP
+	Upgrade() error
+	// UpgradeHandler returns authenticated admin handler triggering Upgrade by POST
+	UpgradeHandler() http.HandlerFunc
 }
 
 type Options struct {
 	// Pidfile define custom Pidfile, default ""
 	Pidfile string
-	// UpgradeTimeout wait period for new app to be ready
+	// UpgradeTimeout wait period for new app to be ready, default 1m
 	UpgradeTimeout time.Duration
-	// ShutdownTimeout wait period to shutdown old app
+	// ReadyTimeout wait period of new app for its readiness and liveness to pass before
+	// it signals ready to old app, it must be shorter than UpgradeTimeout, default UpgradeTimeout/2
+	ReadyTimeout time.Duration
+	// PropagationDelay wait period after app is marked not ready before listeners are closed,
+	// it gives load balancers time to observe failing readiness
+	PropagationDelay time.Duration
+	// ShutdownTimeout wait period to drain in-flight requests, connections still active are closed, default 30s
 	ShutdownTimeout time.Duration
-	// Network define tcp or udp. (default: tcp)
+	// HookTimeout wait period of each shutdown hook, default 5s
+	HookTimeout time.Duration
+	// Network define network of http(s), telemetry and admin servers, tcp, tcp4, tcp6 or unix. (default: tcp)
 	Network string
+	// Admin defines admin server triggering upgrades
+	Admin AdminOptions
 }
 
 type app struct {
 	logger     log.Logger
+	health     health.Health
 	telemetry  telemetry.Telemetry
 	httpServer httpserver.HTTPServer
 	Upgrader   *tableflip.Upgrader
 	Error      error
 	Options    Options
 	SigHUP     chan os.Signal
+	SigTerm    chan os.Signal
+	components application.App
+	mu         *sync.Mutex
+	servers    []Server
+	upgrading  int32
+	upgraded   int32
 }
 
-// Init initialize GraceApp Upgrader and starts telemetry servers and http servers.
-func Init(logger log.Logger, tele telemetry.Telemetry, httpserver httpserver.HTTPServer, opt Options) App {
+// Init initialize GraceApp Upgrader and registers http(s) servers and telemetry servers if any,
+// other servers e.g. gRPC are added by Register. SIGTERM and SIGINT stop the app gracefully.
+// Health lifecycle is driven by Serve: serving once upgrader is ready, draining
+// as soon as upgrader exits and stopped after servers are shut down. Logger and
+// health are registered as the first shutdown hooks, so they are stopped last.
+func Init(logger log.Logger, hl health.Health, tele telemetry.Telemetry, httpserver httpserver.HTTPServer, opt Options) App {
+	if opt.Network == "" {
+		opt.Network = `tcp`
+	}
+	if opt.ShutdownTimeout <= 0 {
+		opt.ShutdownTimeout = defaultShutdownTimeout
+	}
+	if opt.HookTimeout <= 0 {
+		opt.HookTimeout = defaultHookTimeout
+	}
+	if opt.UpgradeTimeout <= 0 {
+		opt.UpgradeTimeout = defaultUpgradeTimeout
+	}
+	if opt.ReadyTimeout <= 0 {
+		opt.ReadyTimeout = opt.UpgradeTimeout / 2
+	}
+	// new app must signal ready or abort before old app gives up waiting for it
+	if opt.ReadyTimeout >= opt.UpgradeTimeout {
+		err := errors.NewWithCode(EcodeInvalidReadyTimeout, `%s ReadyTimeout %s must be shorter than UpgradeTimeout %s`, _FAILED, opt.ReadyTimeout, opt.UpgradeTimeout)
+		logger.Fatal(err)
+	}
+	if opt.Admin.Endpoint == "" {
+		opt.Admin.Endpoint = `/upgrade`
+	}
 	upg, err := tableflip.New(tableflip.Options{UpgradeTimeout: opt.UpgradeTimeout, PIDFile: opt.Pidfile})
 	if err != nil {
 		err = errors.WrapWithCode(err, EcodeTableFlipFailed, errGrace, _FAILED)
 		logger.Fatal(err)
 	}
 	logger.Info(_OK, infoGrace)
-
-	if opt.Network == "" {
-		opt.Network = `tcp`
-	}
 	gs := &app{
 		logger:     logger,
+		health:     hl,
 		telemetry:  tele,
 		httpServer: httpserver,
 		Upgrader:   upg,
 		Error:      err,
 		Options:    opt,
-		SigHUP:     make(chan os.Signal, 0),
+		SigHUP:     make(chan os.Signal, 1),
+		SigTerm:    make(chan os.Signal, 1),
+		components: application.Init(logger, application.Options{StopTimeout: opt.HookTimeout}),
+		mu:         &sync.Mutex{},
+	}
+	gs.servers = append(httpServers(httpserver, opt.Network), telemetryServers(tele, opt.Network)...)
+	if opt.Admin.Address != "" {
+		gs.servers = append(gs.servers, newAdminServer(gs))
 	}
+	// readiness waits for Serve rather than only for checks
+	hl.SetState(health.StateStarting)
+	gs.OnShutdown(`logger`, application.Stoppable(logger).Stop)
+	gs.OnShutdown(`health`, application.Stoppable(hl).Stop, `logger`)
 	signal.Notify(gs.SigHUP, syscall.SIGHUP)
 	go gs.sighup()
+	signal.Notify(gs.SigTerm, syscall.SIGTERM, syscall.SIGINT)
+	go gs.sigterm()
 	return gs
 }
 
@@ -84,58 +154,34 @@ func (g *app) Stop() {
 	g.Upgrader.Stop()
 }
 
-// sighup handle sighup signal
-func (g *app) sighup() {
-	for range g.SigHUP {
-		err := g.Upgrader.Upgrade()
-		if err != nil {
-			err = errors.WrapWithCode(err, EcodeUpgradeFailed, errGrace, _FAILED)
-			g.logger.Fatal(err)
-			continue
-		}
-		g.logger.Info(_UPGRADE, infoGrace)
-	}
-}
-
-// Server starts apps including all http(s) servers and configured telemetry servers if any
+// Serve starts shutdown hooks and all registered servers on listeners inherited from parent process if any
 func (g *app) Serve() {
-	// get all http(s) servers
-	for _, s := range g.httpServer.GetServers() {
-		ln, err := g.Upgrader.Fds.Listen(g.Options.Network, s.Addr)
-		if err != nil {
-			err = errors.WrapWithCode(err, EcodeHTTPServerFailed, errTCP, _FAILED)
-			g.logger.Fatal(err)
+	if err := g.components.Start(context.Background()); err != nil {
+		err = errors.WrapWithCode(err, EcodeStartFailed, errGrace, _FAILED)
+		g.logger.Fatal(err)
+	}
+	for _, s := range g.registered() {
+		for _, addr := range s.Addrs() {
+			if err := g.listen(s, addr); err != nil {
+				err = errors.WrapWithCode(err, EcodeListenFailed, errListen, _FAILED, addr)
+				g.logger.Fatal(err)
+			}
+			g.logger.Info(_OK, infoListen, fmt.Sprintf("[%s]@%s", addr.Network, addr.Address))
 		}
-		g.logger.Info(_OK, infoTCP, fmt.Sprintf("@%s", s.Addr))
-		go g.httpServer.Serve(s.Mode, ln)
 	}
 
-	//get all telemetry servers
-	telemetryServers := g.telemetry.GetServers()
-	for _, s := range telemetryServers {
-		ln, err := g.Upgrader.Fds.Listen(g.Options.Network, s.Addr)
-		if err != nil {
-			err = errors.WrapWithCode(err, EcodeTelemetryServerFailed, errTCP, _FAILED)
-			g.logger.Fatal(err)
+	g.health.SetState(health.StateServing)
+	// new process signals ready only once its checks pass, old process keeps serving otherwise
+	if g.Upgrader.HasParent() {
+		if err := g.waitReady(); err != nil {
+			g.abort(err)
 		}
-		g.logger.Info(_OK, infoTCP, fmt.Sprintf("@%s", s.Addr))
-		go g.telemetry.Serve(s.Mode, ln)
 	}
-
 	if err := g.Upgrader.Ready(); err != nil {
 		err = errors.WrapWithCode(err, EcodeAppNotReady, errGrace, _FAILED)
 		g.logger.Fatal(err)
 	}
 
 	<-g.Upgrader.Exit()
-
-	time.AfterFunc(g.Options.ShutdownTimeout, func() {
-		err := errors.NewWithCode(EcodeAppShutdownFailed, `force Shutdown`, errGrace, _FAILED)
-		g.logger.Fatal(err)
-		os.Exit(1)
-	})
-
-	g.httpServer.Shutdown()
-	g.telemetry.Shutdown()
+	g.shutdown()
 }
-
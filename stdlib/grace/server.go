@@ -0,0 +1,155 @@
+package grace
+
+import (
+	"context"
+	"net"
+	"os"
+	"time"
+
+	errors "github.com/mytoko2796/sdk-go/stdlib/error"
+	"github.com/mytoko2796/sdk-go/stdlib/httpserver"
+	"github.com/mytoko2796/sdk-go/stdlib/telemetry"
+)
+
+// Addr is address a server listens on. Network is tcp, tcp4, tcp6 or unix for
+// stream listeners and udp, udp4, udp6 or unixgram for packet connections.
+type Addr struct {
+	Network string
+	Address string
+}
+
+func (a Addr) String() string {
+	return a.Network + "://" + a.Address
+}
+
+// Server is served on listeners inherited across upgrades e.g. gRPC or raw TCP server
+type Server interface {
+	// Addrs returns addresses to listen on, Serve is called for each stream address
+	Addrs() []Addr
+	// Serve accepts connections on ln until the server is shut down
+	Serve(ln net.Listener)
+	// Shutdown stops accepting and waits for in-flight requests until ctx is done
+	Shutdown(ctx context.Context) error
+}
+
+// PacketServer is Server with packet addresses, ServePacket is called for each packet address
+type PacketServer interface {
+	Server
+	// ServePacket reads packets from conn until the server is shut down
+	ServePacket(conn net.PacketConn)
+}
+
+func (g *app) Register(s Server) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.servers = append(g.servers, s)
+}
+
+func (g *app) registered() []Server {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]Server(nil), g.servers...)
+}
+
+// listen starts serving s on addr, listener of parent process is reused after upgrade
+func (g *app) listen(s Server, addr Addr) error {
+	if isPacket(addr.Network) {
+		ps, ok := s.(PacketServer)
+		if !ok {
+			return errors.NewWithCode(EcodeNotPacketServer, `Server of %s does not serve packet connections`, addr)
+		}
+		conn, err := g.Upgrader.Fds.PacketConn(addr.Network, addr.Address)
+		if err != nil {
+			return err
+		}
+		if conn == nil {
+			removeStaleSocket(addr)
+			if conn, err = g.Upgrader.Fds.ListenPacket(addr.Network, addr.Address); err != nil {
+				return err
+			}
+		}
+		go ps.ServePacket(conn)
+		return nil
+	}
+
+	ln, err := g.Upgrader.Fds.Listener(addr.Network, addr.Address)
+	if err != nil {
+		return err
+	}
+	if ln == nil {
+		removeStaleSocket(addr)
+		if ln, err = g.Upgrader.Fds.Listen(addr.Network, addr.Address); err != nil {
+			return err
+		}
+	}
+	go s.Serve(ln)
+	return nil
+}
+
+func isPacket(network string) bool {
+	switch network {
+	case `udp`, `udp4`, `udp6`, `unixgram`:
+		return true
+	}
+	return false
+}
+
+// removeStaleSocket removes socket file left by a process which did not exit
+// cleanly, socket still accepting connections e.g. of another process is kept
+func removeStaleSocket(addr Addr) {
+	if addr.Network != `unix` && addr.Network != `unixgram` {
+		return
+	}
+	fi, err := os.Stat(addr.Address)
+	if err != nil || fi.Mode()&os.ModeSocket == 0 {
+		return
+	}
+	if conn, err := net.DialTimeout(addr.Network, addr.Address, time.Second); err == nil {
+		conn.Close()
+		return
+	}
+	os.Remove(addr.Address)
+}
+
+// modeServer adapts a server of httpserver or telemetry. Servers are shut down
+// by their package in shutdown, since telemetry also stops its exporters.
+type modeServer struct {
+	addr  Addr
+	serve func(ln net.Listener)
+}
+
+func (s *modeServer) Addrs() []Addr {
+	return []Addr{s.addr}
+}
+
+func (s *modeServer) Serve(ln net.Listener) {
+	s.serve(ln)
+}
+
+func (s *modeServer) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func httpServers(h httpserver.HTTPServer, network string) []Server {
+	var servers []Server
+	for _, s := range h.GetServers() {
+		mode := s.Mode
+		servers = append(servers, &modeServer{
+			addr:  Addr{Network: network, Address: s.Addr},
+			serve: func(ln net.Listener) { h.Serve(mode, ln) },
+		})
+	}
+	return servers
+}
+
+func telemetryServers(t telemetry.Telemetry, network string) []Server {
+	var servers []Server
+	for _, s := range t.GetServers() {
+		mode := s.Mode
+		servers = append(servers, &modeServer{
+			addr:  Addr{Network: network, Address: s.Addr},
+			serve: func(ln net.Listener) { t.Serve(mode, ln) },
+		})
+	}
+	return servers
+}
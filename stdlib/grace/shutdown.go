@@ -0,0 +1,63 @@
+package grace
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	application "github.com/mytoko2796/sdk-go/stdlib/app"
+	errors "github.com/mytoko2796/sdk-go/stdlib/error"
+	"github.com/mytoko2796/sdk-go/stdlib/health"
+)
+
+const (
+	infoShutdown string = `Grace Shutdown:`
+	errShutdown  string = `%s Grace Shutdown Error`
+
+	defaultShutdownTimeout = 30 * time.Second
+	defaultHookTimeout     = 5 * time.Second
+)
+
+// ShutdownFunc releases resources of a component, it should return once ctx is done
+type ShutdownFunc func(ctx context.Context) error
+
+// OnShutdown registers hook as component stopped by Stop of app registry, failure is logged
+func (g *app) OnShutdown(name string, hook ShutdownFunc, deps ...string) {
+	if err := g.components.Register(name, application.Hooks{OnStop: hook}, deps...); err != nil {
+		g.logger.Error(errors.WrapWithCode(err, EcodeAppShutdownFailed, errShutdown, _FAILED))
+	}
+}
+
+// shutdown marks app not ready, waits for propagation delay, drains servers
+// and stops shutdown hooks within HookTimeout each. After successful upgrade
+// new process already serves the same listeners, so readiness is left as is.
+func (g *app) shutdown() {
+	if atomic.LoadInt32(&g.upgraded) == 0 {
+		g.health.SetState(health.StateDraining)
+		if g.Options.PropagationDelay > 0 {
+			g.logger.Info(_OK, infoShutdown, fmt.Sprintf("waiting %s for readiness to propagate", g.Options.PropagationDelay))
+			time.Sleep(g.Options.PropagationDelay)
+		}
+	}
+
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), g.Options.ShutdownTimeout)
+	// telemetry goes last to keep exporting metrics while other servers drain
+	drains := []func(ctx context.Context) error{g.httpServer.Shutdown}
+	for _, s := range g.registered() {
+		drains = append(drains, s.Shutdown)
+	}
+	drains = append(drains, g.telemetry.Shutdown)
+	for _, drain := range drains {
+		if err := drain(ctx); err != nil {
+			g.logger.Error(errors.WrapWithCode(err, EcodeAppShutdownFailed, errShutdown, _FAILED))
+		}
+	}
+	cancel()
+	g.logger.Info(_OK, infoShutdown, fmt.Sprintf("servers drained in %s", time.Since(start)))
+	g.health.SetState(health.StateStopped)
+
+	// failures are logged by app registry
+	g.components.Stop(context.Background())
+}
@@ -0,0 +1,190 @@
+package grace
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	errors "github.com/mytoko2796/sdk-go/stdlib/error"
+	"github.com/mytoko2796/sdk-go/stdlib/telemetry/stat"
+	"github.com/mytoko2796/sdk-go/stdlib/telemetry/tag"
+	"go.opencensus.io/stats"
+	tags "go.opencensus.io/tag"
+)
+
+const (
+	// UpgradeSucceeded is result of upgrade once new process is ready
+	UpgradeSucceeded string = `succeeded`
+	// UpgradeFailed is result of upgrade which failed or timed out, old process keeps serving
+	UpgradeFailed string = `failed`
+	// UpgradeRejected is result of upgrade requested while another one is in progress
+	UpgradeRejected string = `rejected`
+	// UpgradeNotReady is result of new process which did not become ready
+	UpgradeNotReady string = `not_ready`
+
+	// defaultUpgradeTimeout is default UpgradeTimeout of tableflip
+	defaultUpgradeTimeout = time.Minute
+	readyPollInterval     = 100 * time.Millisecond
+)
+
+// AdminOptions defines admin http server triggering upgrades
+type AdminOptions struct {
+	// Address of admin server e.g. 127.0.0.1:8081, empty disables admin server
+	Address string
+	// Endpoint triggering upgrade by POST, default /upgrade
+	Endpoint string
+	// Token is required as bearer token, endpoint rejects every request without token
+	Token string
+}
+
+type upgradeResponse struct {
+	Upgraded bool   `json:"upgraded"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Upgrade starts new process with inherited listeners and returns once it is ready.
+// Failed or timed out upgrade keeps this process serving.
+func (g *app) Upgrade() error {
+	// guard is kept after success since this process is exiting
+	if !atomic.CompareAndSwapInt32(&g.upgrading, 0, 1) {
+		g.recordUpgrade(UpgradeRejected, 0)
+		return ErrUpgradeInProgress
+	}
+	g.logger.Info(_OK, infoGrace, `upgrading`)
+	start := time.Now()
+	if err := g.Upgrader.Upgrade(); err != nil {
+		atomic.StoreInt32(&g.upgrading, 0)
+		err = errors.WrapWithCode(err, EcodeUpgradeFailed, errGrace, _FAILED)
+		g.logger.Error(err)
+		g.recordUpgrade(UpgradeFailed, time.Since(start))
+		return err
+	}
+	atomic.StoreInt32(&g.upgraded, 1)
+	g.recordUpgrade(UpgradeSucceeded, time.Since(start))
+	g.logger.Info(_UPGRADE, infoGrace, fmt.Sprintf("new process is ready in %s", time.Since(start)))
+	return nil
+}
+
+// sighup handle sighup signal, one signal arriving during upgrade is kept buffered and upgrades again
+func (g *app) sighup() {
+	for range g.SigHUP {
+		g.Upgrade()
+	}
+}
+
+// sigterm handle sigterm and sigint signals
+func (g *app) sigterm() {
+	sig := <-g.SigTerm
+	g.logger.Info(_OK, infoGrace, fmt.Sprintf("%s received, stopping", sig))
+	g.Stop()
+}
+
+// waitReady blocks until app is ready and healthy or ReadyTimeout elapses
+func (g *app) waitReady() error {
+	timeout := time.NewTimer(g.Options.ReadyTimeout)
+	defer timeout.Stop()
+	ticker := time.NewTicker(readyPollInterval)
+	defer ticker.Stop()
+	for {
+		err := g.health.IsReadyAndHealthy()
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ticker.C:
+		case <-timeout.C:
+			return errors.WrapWithCode(err, EcodeAppNotReady, `%s app is not ready after %s`, _FAILED, g.Options.ReadyTimeout)
+		}
+	}
+}
+
+// abort shuts down new process which did not become ready, parent process
+// sees the exit and keeps serving
+func (g *app) abort(err error) {
+	g.logger.Error(err)
+	g.recordUpgrade(UpgradeNotReady, 0)
+	g.shutdown()
+	os.Exit(1)
+}
+
+func (g *app) recordUpgrade(result string, latency time.Duration) {
+	ctx := context.Background()
+	mutators := []tags.Mutator{tags.Upsert(tag.TagGraceResult, result)}
+	stats.RecordWithTags(ctx, mutators, stat.StatGraceUpgrade.M(1))
+	if latency > 0 {
+		stats.RecordWithTags(ctx, mutators, stat.StatGraceUpgradeLatency.M(float64(latency)/float64(time.Millisecond)))
+	}
+}
+
+// UpgradeHandler returns authenticated admin handler, POST upgrades and responds once
+// new process is ready, 409 if upgrade is in progress and 500 if upgrade failed
+func (g *app) UpgradeHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !g.authorized(r) {
+			g.logger.WarnWithContext(r.Context(), errors.NewWithCode(EcodeUnauthorized, `unauthorized upgrade request from %s`, r.RemoteAddr))
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodPost {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		code, resp := http.StatusOK, upgradeResponse{Upgraded: true}
+		if err := g.Upgrade(); err != nil {
+			code, resp = http.StatusInternalServerError, upgradeResponse{Error: err.Error()}
+			if err == ErrUpgradeInProgress {
+				code = http.StatusConflict
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// authorized compares bearer token in constant time, no token configured denies all
+func (g *app) authorized(r *http.Request) bool {
+	if g.Options.Admin.Token == "" {
+		return false
+	}
+	auth := r.Header.Get("Authorization")
+	const prefix = "Bearer "
+	if len(auth) < len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(auth[len(prefix):]), []byte(g.Options.Admin.Token)) == 1
+}
+
+// adminServer serves UpgradeHandler, its listener is inherited like any other server
+type adminServer struct {
+	addr   Addr
+	server *http.Server
+}
+
+func newAdminServer(g *app) *adminServer {
+	mux := http.NewServeMux()
+	mux.HandleFunc(g.Options.Admin.Endpoint, g.UpgradeHandler())
+	return &adminServer{
+		addr:   Addr{Network: g.Options.Network, Address: g.Options.Admin.Address},
+		server: &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second},
+	}
+}
+
+func (s *adminServer) Addrs() []Addr {
+	return []Addr{s.addr}
+}
+
+func (s *adminServer) Serve(ln net.Listener) {
+	s.server.Serve(ln)
+}
+
+func (s *adminServer) Shutdown(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
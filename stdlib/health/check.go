@@ -0,0 +1,279 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	errors "github.com/mytoko2796/sdk-go/stdlib/error"
+)
+
+// Status of a check or a probe
+type Status string
+
+const (
+	// StatusUnknown is status before check has enough results
+	StatusUnknown Status = `unknown`
+	// StatusUp means check passes
+	StatusUp Status = `up`
+	// StatusDown means check fails
+	StatusDown Status = `down`
+	// StatusDegraded means probe passes while some non critical checks fail
+	StatusDegraded Status = `degraded`
+)
+
+// Criticality defines how failing check affects its probes
+type Criticality string
+
+const (
+	// CriticalityCritical fails the probe when check fails
+	CriticalityCritical Criticality = `critical`
+	// CriticalityDegraded only marks the probe degraded when check fails
+	CriticalityDegraded Criticality = `degraded`
+)
+
+const (
+	defaultCheckInterval = 10 * time.Second
+	defaultCheckTimeout  = time.Second
+	// defaultMaxWaitingTime keeps Init from blocking forever when MaxWaitingTime is not set
+	defaultMaxWaitingTime = time.Second
+)
+
+// CheckFunc returns nil if dependency is healthy. It should return once ctx is done.
+// Built-in checks of SDK components are provided by package health/checks.
+type CheckFunc func(ctx context.Context) error
+
+// Check is a named check registered by Init
+type Check struct {
+	Name    string
+	Func    CheckFunc
+	Options CheckOptions
+}
+
+// CheckOptions defines schedule and thresholds of a check
+type CheckOptions struct {
+	// Probes the check belongs to, default readiness
+	Probes []Probe
+	// Criticality is critical or degraded, default critical
+	Criticality Criticality
+	// Timeout of single check, default 1s
+	Timeout time.Duration
+	// Interval between checks, default 10s
+	Interval time.Duration
+	// InitialDelay before the first check
+	InitialDelay time.Duration
+	// SuccessThreshold is number of consecutive successes to become up, default 1
+	SuccessThreshold int
+	// FailureThreshold is number of consecutive failures to become down, default 1
+	FailureThreshold int
+}
+
+type check struct {
+	name string
+	f    CheckFunc
+	opt  CheckOptions
+
+	// fields below are guarded by status.mu
+	status         Status
+	err            error
+	latency        time.Duration
+	lastCheck      time.Time
+	lastTransition time.Time
+	successes      int
+	failures       int
+}
+
+func (h *health) Register(name string, f CheckFunc, opt CheckOptions) error {
+	if f == nil {
+		return errors.NewWithCode(EcodeCheckFuncIsNil, `Check Function of %s is Nil`, name)
+	}
+	if len(opt.Probes) == 0 {
+		opt.Probes = []Probe{ProbeReadiness}
+	}
+	if opt.Criticality == "" {
+		opt.Criticality = CriticalityCritical
+	}
+	if opt.Timeout <= 0 {
+		opt.Timeout = defaultCheckTimeout
+	}
+	if opt.Interval <= 0 {
+		opt.Interval = defaultCheckInterval
+	}
+	if opt.SuccessThreshold <= 0 {
+		opt.SuccessThreshold = 1
+	}
+	if opt.FailureThreshold <= 0 {
+		opt.FailureThreshold = 1
+	}
+	c := &check{
+		name:           name,
+		f:              f,
+		opt:            opt,
+		status:         StatusUnknown,
+		lastTransition: time.Now(),
+	}
+
+	var err error
+	h.update(func() {
+		s := h.status
+		for _, p := range opt.Probes {
+			if _, ok := s.probes[p]; !ok {
+				err = errors.NewWithCode(EcodeUnknownProbe, `Unknown probe %s of check %s`, p, name)
+				return
+			}
+		}
+		if _, ok := s.checks[name]; ok {
+			err = errors.NewWithCode(EcodeCheckAlreadyRegistered, `Check %s is already registered`, name)
+			return
+		}
+		s.checks[name] = c
+		h.evaluate()
+	})
+	if err != nil {
+		return err
+	}
+	go h.run(c)
+	return nil
+}
+
+// run checks at interval until health is stopped
+func (h *health) run(c *check) {
+	select {
+	case <-time.After(c.opt.InitialDelay):
+	case <-h.done:
+		return
+	}
+	h.logger.Info(_OK, infoHealth, fmt.Sprintf("[%s] starts after %s delay", c.name, c.opt.InitialDelay))
+
+	ticker := time.NewTicker(c.opt.Interval)
+	defer ticker.Stop()
+	for {
+		start := time.Now()
+		err := c.call()
+		h.setResult(c, err, time.Since(start))
+		select {
+		case <-ticker.C:
+		case <-h.done:
+			return
+		}
+	}
+}
+
+// call runs check function, check ignoring its context is reported failed on timeout
+func (c *check) call() error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.opt.Timeout)
+	defer cancel()
+	result := make(chan error, 1)
+	go func() {
+		result <- c.f(ctx)
+	}()
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf(`check %s timed out after %s`, c.name, c.opt.Timeout)
+	}
+}
+
+// setResult updates check status once threshold is reached
+func (h *health) setResult(c *check, err error, latency time.Duration) {
+	h.updateLogged(func() func() {
+		if h.status.stopped {
+			return nil
+		}
+		c.err = err
+		c.latency = latency
+		c.lastCheck = time.Now()
+		old, new := c.status, c.status
+		if err != nil {
+			c.failures++
+			c.successes = 0
+			if c.failures >= c.opt.FailureThreshold {
+				new = StatusDown
+			}
+		} else {
+			c.successes++
+			c.failures = 0
+			if c.successes >= c.opt.SuccessThreshold {
+				new = StatusUp
+			}
+		}
+		if new != old {
+			c.status = new
+			c.lastTransition = c.lastCheck
+		}
+		h.evaluate()
+		return func() {
+			h.recordCheck(c.name, old, new, err, latency)
+		}
+	})
+}
+
+// evaluate sets probe status from its checks, probe without checks is up. Startup
+// probe without checks follows readiness and liveness checks and stays up once passed.
+// It requires status.mu to be locked.
+func (h *health) evaluate() {
+	s := h.status
+	startupChecks := 0
+	for p := range s.probes {
+		status, checks := StatusUp, 0
+		for _, c := range s.checks {
+			if !c.in(p) {
+				continue
+			}
+			checks++
+			switch {
+			case c.status == StatusUp:
+			// check without enough results is not evaluated yet whatever its criticality
+			case c.status == StatusUnknown:
+				if status != StatusDown {
+					status = StatusUnknown
+				}
+			case c.opt.Criticality == CriticalityDegraded:
+				if status == StatusUp {
+					status = StatusDegraded
+				}
+			default:
+				status = StatusDown
+			}
+		}
+		if p == ProbeStartup {
+			startupChecks = checks
+		}
+		s.probes[p] = status
+	}
+	if s.started {
+		s.probes[ProbeStartup] = StatusUp
+		return
+	}
+	if !s.initialized {
+		s.probes[ProbeStartup] = StatusUnknown
+		return
+	}
+	if startupChecks == 0 {
+		s.probes[ProbeStartup] = worst(s.probes[ProbeReadiness], s.probes[ProbeLiveness])
+	}
+	switch s.probes[ProbeStartup] {
+	case StatusUp, StatusDegraded:
+		s.started = true
+		close(h.started)
+	}
+}
+
+// worst returns the least healthy status
+func worst(a, b Status) Status {
+	rank := map[Status]int{StatusUp: 0, StatusDegraded: 1, StatusUnknown: 2, StatusDown: 3}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
+
+func (c *check) in(p Probe) bool {
+	for _, probe := range c.opt.Probes {
+		if probe == p {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+// Package checks provides health checks of SDK components and common dependencies.
+// Every check returns health.CheckFunc to be used in health.Options Checks.
+package checks
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/mytoko2796/sdk-go/stdlib/config"
+	"github.com/mytoko2796/sdk-go/stdlib/health"
+	"github.com/mytoko2796/sdk-go/stdlib/sql"
+)
+
+// SQL pings leader and follower, a distinct follower is checked too. It fails if
+// ratio of connections in use to max open connections exceeds maxInUse, e.g. 0.9.
+// Zero maxInUse or unlimited pool skips saturation check.
+func SQL(db sql.SQL, maxInUse float64) health.CheckFunc {
+	return func(ctx context.Context) error {
+		commands := map[string]sql.Command{`leader`: db.Leader()}
+		if f := db.Follower(); f != nil && f != db.Leader() {
+			commands[`follower`] = f
+		}
+		for name, cmd := range commands {
+			if err := cmd.Ping(ctx); err != nil {
+				return fmt.Errorf(`%s %s ping failed: %v`, db.Driver(), name, err)
+			}
+			st := cmd.GetStats()
+			if maxInUse <= 0 || st.MaxOpenConnections <= 0 {
+				continue
+			}
+			if ratio := float64(st.InUse) / float64(st.MaxOpenConnections); ratio > maxInUse {
+				return fmt.Errorf(`%s %s pool is saturated, %d of %d connections in use`, db.Driver(), name, st.InUse, st.MaxOpenConnections)
+			}
+		}
+		return nil
+	}
+}
+
+// HTTP sends GET request to url and expects status, zero status accepts any 2xx
+func HTTP(url string, status int) health.CheckFunc {
+	client := &http.Client{}
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		io.Copy(ioutil.Discard, resp.Body)
+		if status == 0 && resp.StatusCode >= 200 && resp.StatusCode <= 299 || resp.StatusCode == status {
+			return nil
+		}
+		return fmt.Errorf(`GET %s responded %s`, url, resp.Status)
+	}
+}
+
+// TCP dials addr e.g. redis:6379
+func TCP(addr string) health.CheckFunc {
+	return func(ctx context.Context) error {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+}
+
+// DNS resolves host
+func DNS(host string) health.CheckFunc {
+	return func(ctx context.Context) error {
+		addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+		if err != nil {
+			return err
+		}
+		if len(addrs) == 0 {
+			return fmt.Errorf(`%s has no address`, host)
+		}
+		return nil
+	}
+}
+
+// Disk fails if free space of filesystem containing path is below minFree bytes
+func Disk(path string, minFree uint64) health.CheckFunc {
+	return func(ctx context.Context) error {
+		free, err := diskFree(path)
+		if err != nil {
+			return err
+		}
+		if free < minFree {
+			return fmt.Errorf(`%s has %d bytes free, below %d`, path, free, minFree)
+		}
+		return nil
+	}
+}
+
+// Goroutine fails if number of goroutines exceeds max, e.g. on goroutine leak
+func Goroutine(max int) health.CheckFunc {
+	return func(ctx context.Context) error {
+		if n := runtime.NumGoroutine(); n > max {
+			return fmt.Errorf(`%d goroutines exceed %d`, n, max)
+		}
+		return nil
+	}
+}
+
+// Heap fails if allocated heap exceeds max bytes. It reads runtime memory
+// statistics which briefly stops the world, so it should not run too often.
+func Heap(max uint64) health.CheckFunc {
+	return func(ctx context.Context) error {
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+		if m.HeapAlloc > max {
+			return fmt.Errorf(`heap %d bytes exceeds %d`, m.HeapAlloc, max)
+		}
+		return nil
+	}
+}
+
+// Config fails while remote configuration is served from last-known-good cache or
+// its last successful fetch is older than maxAge, e.g. a few watch periods. Zero maxAge
+// only checks the cache. It is usually registered with degraded criticality.
+func Config(conf config.Conf, maxAge time.Duration) health.CheckFunc {
+	return func(ctx context.Context) error {
+		if err := conf.Status(); err != nil {
+			return err
+		}
+		if age := conf.Age(); maxAge > 0 && age > maxAge {
+			return fmt.Errorf(`configuration was last fetched %s ago, older than %s`, age.Round(time.Second), maxAge)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !(linux || darwin || freebsd || dragonfly)
+
+package checks
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// diskFree is not supported since free space is read by statfs
+func diskFree(path string) (uint64, error) {
+	return 0, fmt.Errorf(`disk check is not supported on %s`, runtime.GOOS)
+}
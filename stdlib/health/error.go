@@ -13,14 +13,23 @@ const (
 	EcodeNotHealthy
 	EcodeNotReady
 	EcodeNotReadyAndHealthy
+	EcodeCheckFuncIsNil
+	EcodeUnknownProbe
+	EcodeCheckAlreadyRegistered
+	EcodeStartupCheckFuncIsNil
+	EcodeNotStarted
+	EcodeMaintenance
+	EcodeUnauthorized
 )
 
 var (
 	// Error Health Package
-	ErrAppInitFailed        = errors.NewWithCode(EcodeAppInitFailed, `MaxWaitingTime is elapsed before App is ready & healthy! - Application cannot be started`, errHealth, _FAILED)
-	ErrReadyCheckFuncIsNil  = errors.NewWithCode(EcodeReadyCheckFuncIsNil, `Readinesscheck Function is Nil`, errHealth, _FAILED)
-	ErrHealthCheckFuncIsNil = errors.NewWithCode(EcodeHealthyCheckFuncIsNil, `Healthcheck Function is Nil`, errHealth, _FAILED)
-	ErrNotHealthy           = errors.NewWithCode(EcodeNotHealthy, `app is not healthy`, errHealth, _FAILED)
-	ErrNotReady             = errors.NewWithCode(EcodeNotReady, `app is not ready`, errHealth, _FAILED)
-	ErrNotReadyAndHealthy   = errors.NewWithCode(EcodeNotReadyAndHealthy, `app is not ready nor healthy`, errHealth, _FAILED)
-)
\ No newline at end of file
+	ErrAppInitFailed         = errors.NewWithCode(EcodeAppInitFailed, `MaxWaitingTime is elapsed before App is started! - Application cannot be started`, errHealth, _FAILED)
+	ErrReadyCheckFuncIsNil   = errors.NewWithCode(EcodeReadyCheckFuncIsNil, `Readinesscheck Function is Nil`, errHealth, _FAILED)
+	ErrHealthCheckFuncIsNil  = errors.NewWithCode(EcodeHealthyCheckFuncIsNil, `Healthcheck Function is Nil`, errHealth, _FAILED)
+	ErrNotHealthy            = errors.NewWithCode(EcodeNotHealthy, `app is not healthy`, errHealth, _FAILED)
+	ErrNotReady              = errors.NewWithCode(EcodeNotReady, `app is not ready`, errHealth, _FAILED)
+	ErrNotReadyAndHealthy    = errors.NewWithCode(EcodeNotReadyAndHealthy, `app is not ready nor healthy`, errHealth, _FAILED)
+	ErrStartupCheckFuncIsNil = errors.NewWithCode(EcodeStartupCheckFuncIsNil, `Startupcheck Function is Nil`, errHealth, _FAILED)
+	ErrNotStarted            = errors.NewWithCode(EcodeNotStarted, `app is not started`, errHealth, _FAILED)
+)
@@ -0,0 +1,146 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	errors "github.com/mytoko2796/sdk-go/stdlib/error"
+	"github.com/mytoko2796/sdk-go/stdlib/telemetry/stat"
+	"github.com/mytoko2796/sdk-go/stdlib/telemetry/tag"
+	"go.opencensus.io/stats"
+	tags "go.opencensus.io/tag"
+)
+
+// ChangeFunc is called after probe status changes. Err is the cause of failing
+// or degraded status, e.g. the error of the failing check, and nil otherwise.
+type ChangeFunc func(probe Probe, old, new Status, err error)
+
+// probeOrder is order of probe transitions within a single change
+var probeOrder = []Probe{ProbeStartup, ProbeReadiness, ProbeLiveness}
+
+type transition struct {
+	probe Probe
+	old   Status
+	new   Status
+	err   error
+}
+
+// OnChange registers f to be called on probe transitions, including those caused by lifecycle
+func (h *health) OnChange(f ChangeFunc) {
+	s := h.status
+	s.mu.Lock()
+	s.listeners = append(s.listeners, f)
+	s.mu.Unlock()
+}
+
+// update runs f with status.mu locked then logs, records and notifies probe
+// transitions. Listeners are called without the lock so they may use Health.
+func (h *health) update(f func()) {
+	h.updateLogged(func() func() {
+		f()
+		return nil
+	})
+}
+
+// updateLogged is update where f returns func logging the change or nil. It is
+// called without the lock, before probe transitions caused by the change.
+func (h *health) updateLogged(f func() func()) {
+	s := h.status
+	s.mu.Lock()
+	log := f()
+	ts := h.transitions()
+	listeners := s.listeners
+	s.mu.Unlock()
+	if log != nil {
+		log()
+	}
+	for _, t := range ts {
+		h.notify(t, listeners)
+	}
+}
+
+// transitions returns probes whose status changed since last call, it requires status.mu to be locked
+func (h *health) transitions() []transition {
+	s := h.status
+	var ts []transition
+	for _, p := range probeOrder {
+		status := h.probeStatus(p)
+		old := s.reported[p]
+		if status == old {
+			continue
+		}
+		s.reported[p] = status
+		t := transition{probe: p, old: old, new: status}
+		if status != StatusUp {
+			t.err = h.probeCause(p)
+		}
+		ts = append(ts, t)
+	}
+	return ts
+}
+
+// probeCause returns why probe does not pass, it requires status.mu to be locked
+func (h *health) probeCause(p Probe) error {
+	s := h.status
+	if s.state == StateStopped || p == ProbeReadiness && !s.serving() {
+		return fmt.Errorf(`app is %s`, s.state)
+	}
+	if p == ProbeReadiness && !s.started {
+		return ErrNotStarted
+	}
+	if p == ProbeReadiness && s.maintenance {
+		return errors.NewWithCode(EcodeMaintenance, `app is in maintenance: %s`, s.reason)
+	}
+	names := make([]string, 0, len(s.checks))
+	for name := range s.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if c := s.checks[name]; c.in(p) && c.status != StatusUp && c.err != nil {
+			return fmt.Errorf(`check %s: %v`, name, c.err)
+		}
+	}
+	return nil
+}
+
+func (h *health) notify(t transition, listeners []ChangeFunc) {
+	if t.new == StatusUp {
+		h.logger.Infow(`Health: probe status changed`, `probe`, t.probe, `old`, t.old, `new`, t.new)
+	} else {
+		h.logger.Warnw(`Health: probe status changed`, `probe`, t.probe, `old`, t.old, `new`, t.new, `error`, t.err)
+	}
+	probe := []tags.Mutator{tags.Upsert(tag.TagHealthProbe, string(t.probe))}
+	stats.RecordWithTags(context.Background(), probe, stat.StatHealthProbeStatus.M(passing(t.new)))
+	stats.RecordWithTags(context.Background(), append(probe, tags.Upsert(tag.TagHealthStatus, string(t.new))), stat.StatHealthProbeTransition.M(1))
+	for _, f := range listeners {
+		f(t.probe, t.old, t.new, t.err)
+	}
+}
+
+// recordCheck records check status and latency, transitions of the check are logged
+func (h *health) recordCheck(name string, old, new Status, err error, latency time.Duration) {
+	if old != new {
+		if new == StatusUp {
+			h.logger.Infow(`Health: check status changed`, `check`, name, `old`, old, `new`, new)
+		} else {
+			h.logger.Warnw(`Health: check status changed`, `check`, name, `old`, old, `new`, new, `error`, err)
+		}
+	}
+	result := StatusUp
+	if err != nil {
+		result = StatusDown
+	}
+	stats.RecordWithTags(context.Background(), []tags.Mutator{tags.Upsert(tag.TagHealthCheck, name)}, stat.StatHealthCheckStatus.M(passing(new)))
+	stats.RecordWithTags(context.Background(), []tags.Mutator{tags.Upsert(tag.TagHealthCheck, name), tags.Upsert(tag.TagHealthStatus, string(result))},
+		stat.StatHealthCheckLatency.M(float64(latency)/float64(time.Millisecond)))
+}
+
+func passing(s Status) int64 {
+	if s == StatusUp || s == StatusDegraded {
+		return 1
+	}
+	return 0
+}
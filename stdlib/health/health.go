@@ -2,8 +2,8 @@ package health
 
 import (
 	"context"
-	"fmt"
 	log "github.com/mytoko2796/sdk-go/stdlib/logger"
+	"net/http"
 	"sync"
 	"time"
 )
@@ -16,11 +16,16 @@ const (
 	_FAILED string = `[FAILED]`
 )
 
-type probe string
+// Probe names a group of checks
+type Probe string
 
 const (
-	ready probe = `[READINESS CHECK]`
-	live  probe = `[LIVENESS CHECK]`
+	// ProbeReadiness checks decide whether app receives traffic
+	ProbeReadiness Probe = `readiness`
+	// ProbeLiveness checks decide whether app should be restarted
+	ProbeLiveness Probe = `liveness`
+	// ProbeStartup checks must pass once before readiness and liveness are reported
+	ProbeStartup Probe = `startup`
 )
 
 type Health interface {
@@ -28,28 +33,66 @@ type Health interface {
 	HealthEndpoint() string
 	// ReadyEndpoint returns url endpoint of readiness check
 	ReadyEndpoint() string
+	// StartupEndpoint returns url endpoint of startup check
+	StartupEndpoint() string
 	// IsReady returns app readiness status as error interface
 	IsReady() error
 	// IsHealthy returns app liveness status as error interface
 	IsHealthy() error
 	// IsReadyAndHealthy returns readiness and liveness status as error interface
 	IsReadyAndHealthy() error
-	// Stop readiness and liveness checker goroutines.
+	// IsStarted returns startup status as error interface
+	IsStarted() error
+	// SetState moves app lifecycle forward, readiness passes only while serving.
+	// Lifecycle is opt-in, until servers (e.g. httpserver or grace) set a state
+	// readiness follows its checks only.
+	SetState(state State)
+	// State returns current lifecycle state
+	State() State
+	// ProbeStatus returns probe status within lifecycle state
+	ProbeStatus(probe Probe) Status
+	// ChecksStatus returns probe status from its checks only, regardless of lifecycle and maintenance
+	ChecksStatus(probe Probe) Status
+	// Register adds named check to probes defined in options and starts it
+	Register(name string, check CheckFunc, opt CheckOptions) error
+	// Report returns probe status with the last result of every check of the probe
+	Report(probe Probe) Report
+	// OnChange registers f to be called on every probe status transition
+	OnChange(f ChangeFunc)
+	// SetMaintenance takes app out of rotation, readiness fails with reason while liveness stays healthy
+	SetMaintenance(reason string)
+	// ClearMaintenance puts app back into rotation
+	ClearMaintenance()
+	// Maintenance returns reason and whether app is in maintenance
+	Maintenance() (string, bool)
+	// MaintenanceEndpoint returns url endpoint of maintenance admin handler
+	MaintenanceEndpoint() string
+	// MaintenanceHandler returns authenticated admin handler of maintenance mode
+	MaintenanceHandler() http.HandlerFunc
+	// Stop readiness and liveness checker goroutines and set stopped state.
 	// This should be called during app termination to free resource.
 	Stop()
 }
 
 type Options struct {
-	// WaitBeforeContinue Blocking all process before app is ready and healthy
+	// WaitBeforeContinue Blocking all process before startup probe passes
 	WaitBeforeContinue bool
-	// MaxWaitingTime defines max waiting time during blocking time ( WaitBeforeContinue )
+	// MaxWaitingTime defines max waiting time during blocking time ( WaitBeforeContinue ), default 1s, negative waits until started
 	MaxWaitingTime time.Duration
+	// Startup probe configuration, without startup checks startup passes
+	// once readiness and liveness checks pass
+	Startup ProbeOptions
 	// Liveness probe configuration
 	Liveness ProbeOptions
 	// Readiness probe configuration
 	Readiness ProbeOptions
+	// Maintenance defines admin endpoint of maintenance mode
+	Maintenance MaintenanceOptions
+	// Checks are registered on Init, e.g. built-in checks.SQL or checks.HTTP
+	Checks []Check
 }
 
+// ProbeOptions defines check function registered as a check named after the probe
 type ProbeOptions struct {
 	Enabled bool
 	// Success Threshold at respective probe
@@ -72,31 +115,56 @@ type health struct {
 	logger log.Logger
 	status *status
 	opt Options
-	termReady chan struct{}
-	termLive chan struct{}
+	done chan struct{}
+	started chan struct{}
 }
 
 type status struct {
 	mu *sync.RWMutex
-	isHealthy bool
-	isReady bool
+	checks map[string]*check
+	probes map[Probe]Status
+	// reported is probe status within lifecycle as last notified
+	reported map[Probe]Status
+	listeners []ChangeFunc
+	state State
+	maintenance bool
+	reason string
+	since time.Time
+	initialized bool
+	started bool
+	stopped bool
 }
 
 func Init(logger log.Logger, opt Options) Health{
 	health := &health{
 		logger: logger,
 		status: &status{
-			mu:        &sync.RWMutex{},
-			isHealthy: false,
-			isReady:   false,
+			mu:     &sync.RWMutex{},
+			checks: make(map[string]*check),
+			probes: map[Probe]Status{
+				ProbeReadiness: StatusUnknown,
+				ProbeLiveness:  StatusUnknown,
+				ProbeStartup:   StatusUnknown,
+			},
+			reported: map[Probe]Status{
+				ProbeReadiness: StatusUnknown,
+				ProbeLiveness:  StatusUnknown,
+				ProbeStartup:   StatusUnknown,
+			},
+			state: StateUnmanaged,
 		},
-		opt:       opt,
-		termReady: make(chan struct{}, 1),
-		termLive:  make(chan struct{}, 1),
+		opt:     opt,
+		done:    make(chan struct{}),
+		started: make(chan struct{}),
 	}
 	health.runCheckers()
+	// startup is evaluated once all checks of options are registered
+	health.update(func() {
+		health.status.initialized = true
+		health.evaluate()
+	})
 	if opt.WaitBeforeContinue {
-		if err := health.waitUntilReadyAndHealthy(); err != nil {
+		if err := health.waitStarted(); err != nil {
 			logger.Panic(ErrAppInitFailed)
 			return nil
 		}
@@ -104,132 +172,70 @@ func Init(logger log.Logger, opt Options) Health{
 	return health
 }
 
-func (h *health) waitUntilReadyAndHealthy() error {
-	ticker := time.NewTicker(1 * time.Second)
-	maxAttempt := int64(h.opt.MaxWaitingTime)/int64(time.Second) + 1
-	attempt := int64(0)
-	for range ticker.C {
-		if err := h.IsReady(); err == nil {
-			if err := h.IsHealthy(); err == nil {
-				ticker.Stop()
-				break
-			}
-		}
-		attempt++
-		if attempt > maxAttempt {
-			return ErrAppInitFailed
-		}
-	}
-	return nil
-}
-
+// runCheckers registers CheckF of enabled probes. Probe thresholds count
+// results after the first one, checks count consecutive results.
 func (h *health) runCheckers() {
 	if h.opt.Readiness.Enabled {
 		if h.opt.Readiness.CheckF == nil {
 			h.logger.Panic(ErrReadyCheckFuncIsNil)
 			return
 		}
-		go h.AsyncChecker(ready)
+		h.registerProbe(ProbeReadiness, h.opt.Readiness)
 	}
 	if h.opt.Liveness.Enabled {
 		if h.opt.Liveness.CheckF == nil {
 			h.logger.Panic(ErrHealthCheckFuncIsNil)
 			return
 		}
-		go h.AsyncChecker(live)
+		h.registerProbe(ProbeLiveness, h.opt.Liveness)
 	}
-}
-
-func (h *health) AsyncChecker(mode probe) {
-	var (
-		ticker           *time.Ticker
-		successThreshold int
-		failureThreshold int
-		initialDelay     time.Duration
-		timeout          time.Duration
-		f                func(ctx context.Context, canc context.CancelFunc) error
-		failure          int
-		success          int
-		termSig          chan struct{}
-	)
-
-	switch mode {
-	case ready:
-		ticker = time.NewTicker(h.opt.Readiness.PeriodSec)
-		successThreshold = h.opt.Readiness.SuccessThreshold
-		failureThreshold = h.opt.Readiness.FailureThreshold
-		initialDelay := h.opt.Readiness.InitialDelaySec - h.opt.Readiness.PeriodSec
-		if initialDelay < 0 {
-			initialDelay = 0
-		}
-		timeout = h.opt.Readiness.CheckTimeout
-		f = h.opt.Readiness.CheckF
-		termSig = h.termReady
-
-	case live:
-		ticker = time.NewTicker(h.opt.Liveness.PeriodSec)
-		successThreshold = h.opt.Liveness.SuccessThreshold
-		failureThreshold = h.opt.Liveness.FailureThreshold
-		initialDelay = h.opt.Liveness.InitialDelaySec - h.opt.Liveness.PeriodSec
-		if initialDelay < 0 {
-			initialDelay = 0
+	if h.opt.Startup.Enabled {
+		if h.opt.Startup.CheckF == nil {
+			h.logger.Panic(ErrStartupCheckFuncIsNil)
+			return
 		}
-		timeout = h.opt.Liveness.CheckTimeout
-		f = h.opt.Liveness.CheckF
-		termSig = h.termLive
-
+		h.registerProbe(ProbeStartup, h.opt.Startup)
 	}
-	//initial delay sec
-	time.Sleep(initialDelay)
-
-	h.logger.Info(_OK, infoHealth, fmt.Sprintf("%s starts after %s delay", string(mode), initialDelay))
-
-	for {
-		select {
-		case <-ticker.C:
-			ctx, cancel := context.WithTimeout(context.Background(), timeout)
-			err := f(ctx, cancel)
-			if err != nil {
-				failure++
-				if failure > failureThreshold {
-					h.setStatus(mode, false)
-					failure = 0
-					success = 0
-				}
-			} else {
-				success++
-				if success > successThreshold {
-					h.setStatus(mode, true)
-					success = 0
-					failure = 0
-				}
-			}
-		case <-termSig:
-			ticker.Stop()
+	for _, c := range h.opt.Checks {
+		if err := h.Register(c.Name, c.Func, c.Options); err != nil {
+			h.logger.Panic(err)
 			return
 		}
 	}
 }
 
-func (h *health) setStatus(mode probe, _OK bool) {
-	s := h.status
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	if mode == live {
-		s.isHealthy = _OK
-		return
+func (h *health) registerProbe(p Probe, opt ProbeOptions) {
+	f := opt.CheckF
+	check := func(ctx context.Context) error {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		return f(ctx, cancel)
+	}
+	if err := h.Register(string(p), check, CheckOptions{
+		Probes:           []Probe{p},
+		Timeout:          opt.CheckTimeout,
+		Interval:         opt.PeriodSec,
+		InitialDelay:     opt.InitialDelaySec,
+		SuccessThreshold: opt.SuccessThreshold + 1,
+		FailureThreshold: opt.FailureThreshold + 1,
+	}); err != nil {
+		h.logger.Panic(err)
+	}
+}
+
+// probeError returns err if probe is not up nor degraded, it requires status.mu to be locked
+func (h *health) probeError(p Probe, err error) error {
+	switch h.probeStatus(p) {
+	case StatusUp, StatusDegraded:
+		return nil
 	}
-	s.isReady = _OK
-	return
+	return err
 }
 
 func (h *health) IsReady() error {
 	h.status.mu.RLock()
 	defer h.status.mu.RUnlock()
-	if h.status.isReady {
-		return nil
-	}
-	return ErrNotReady
+	return h.probeError(ProbeReadiness, ErrNotReady)
 }
 
 func (h *health) HealthEndpoint() string {
@@ -240,31 +246,38 @@ func (h *health) ReadyEndpoint() string {
 	return h.opt.Readiness.Endpoint
 }
 
+func (h *health) StartupEndpoint() string {
+	return h.opt.Startup.Endpoint
+}
+
 func (h *health) IsHealthy() error {
 	h.status.mu.RLock()
 	defer h.status.mu.RUnlock()
-	if h.status.isHealthy {
-		return nil
-	}
-	return ErrNotHealthy
+	return h.probeError(ProbeLiveness, ErrNotHealthy)
 }
 
 func (h *health) IsReadyAndHealthy() error {
 	h.status.mu.RLock()
 	defer h.status.mu.RUnlock()
-	if h.status.isReady && h.status.isHealthy {
+	if h.probeError(ProbeReadiness, ErrNotReady) == nil && h.probeError(ProbeLiveness, ErrNotHealthy) == nil {
 		return nil
 	}
 	return ErrNotReadyAndHealthy
 }
 
+func (h *health) IsStarted() error {
+	h.status.mu.RLock()
+	defer h.status.mu.RUnlock()
+	return h.probeError(ProbeStartup, ErrNotStarted)
+}
+
 func (h *health) Stop() {
-	if h.opt.Readiness.Enabled {
-		h.setStatus(ready, false)
-		close(h.termReady)
-	}
-	if h.opt.Liveness.Enabled {
-		h.setStatus(live, false)
-		close(h.termLive)
-	}
-}
\ No newline at end of file
+	h.SetState(StateStopped)
+	h.update(func() {
+		if h.status.stopped {
+			return
+		}
+		h.status.stopped = true
+		close(h.done)
+	})
+}
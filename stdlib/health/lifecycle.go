@@ -0,0 +1,107 @@
+package health
+
+import (
+	"fmt"
+	"time"
+)
+
+// State is app lifecycle state
+type State string
+
+const (
+	// StateUnmanaged is state of app without server driving its lifecycle e.g. worker
+	// or test, readiness follows its checks only until another state is set
+	StateUnmanaged State = `unmanaged`
+	// StateStarting is state until servers start serving
+	StateStarting State = `starting`
+	// StateServing is the only state where readiness may pass
+	StateServing State = `serving`
+	// StateDraining is state once shutdown begins, in-flight requests are still served
+	StateDraining State = `draining`
+	// StateStopped is state after servers are shut down
+	StateStopped State = `stopped`
+)
+
+var stateOrder = map[State]int{
+	StateUnmanaged: 0,
+	StateStarting:  1,
+	StateServing:   2,
+	StateDraining:  3,
+	StateStopped:   4,
+}
+
+// SetState moves lifecycle forward, going back to an earlier state is ignored
+// so late servers cannot make draining app ready again
+func (h *health) SetState(state State) {
+	h.updateLogged(func() func() {
+		old := h.status.state
+		if stateOrder[state] <= stateOrder[old] {
+			return nil
+		}
+		h.status.state = state
+		return func() {
+			h.logger.Info(_OK, infoHealth, fmt.Sprintf("[%s] -> [%s]", old, state))
+		}
+	})
+}
+
+func (h *health) State() State {
+	h.status.mu.RLock()
+	defer h.status.mu.RUnlock()
+	return h.status.state
+}
+
+// ProbeStatus returns probe status within lifecycle, unlike IsReady or IsHealthy
+// it tells failing probe apart from probe without results yet
+func (h *health) ProbeStatus(p Probe) Status {
+	h.status.mu.RLock()
+	defer h.status.mu.RUnlock()
+	return h.probeStatus(p)
+}
+
+// ChecksStatus returns probe status from its checks only, e.g. readiness failing
+// by its checks is told apart from readiness failing by maintenance
+func (h *health) ChecksStatus(p Probe) Status {
+	h.status.mu.RLock()
+	defer h.status.mu.RUnlock()
+	return h.status.probes[p]
+}
+
+// probeStatus returns probe status within lifecycle, readiness passes only while
+// serving after startup and out of maintenance. It requires status.mu to be locked.
+func (h *health) probeStatus(p Probe) Status {
+	s := h.status
+	if s.state == StateStopped {
+		return StatusDown
+	}
+	if p == ProbeReadiness && (!s.serving() || !s.started || s.maintenance) {
+		return StatusDown
+	}
+	return s.probes[p]
+}
+
+// serving tells whether lifecycle lets readiness pass, app without server driving
+// its lifecycle is always serving
+func (s *status) serving() bool {
+	return s.state == StateServing || s.state == StateUnmanaged
+}
+
+// waitStarted blocks until startup passes or MaxWaitingTime elapses
+func (h *health) waitStarted() error {
+	wait := h.opt.MaxWaitingTime
+	if wait == 0 {
+		wait = defaultMaxWaitingTime
+	}
+	var timeout <-chan time.Time
+	if wait > 0 {
+		t := time.NewTimer(wait)
+		defer t.Stop()
+		timeout = t.C
+	}
+	select {
+	case <-h.started:
+		return nil
+	case <-timeout:
+		return ErrAppInitFailed
+	}
+}
@@ -0,0 +1,127 @@
+package health
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	errors "github.com/mytoko2796/sdk-go/stdlib/error"
+)
+
+const (
+	defaultMaintenanceReason string = `manual`
+)
+
+// MaintenanceOptions defines admin endpoint switching maintenance mode
+type MaintenanceOptions struct {
+	// Endpoint that will be registered in httpmux, GET reads, PUT sets and DELETE clears maintenance
+	Endpoint string
+	// Token is required as bearer token, endpoint rejects every request without token
+	Token string
+}
+
+type maintenanceRequest struct {
+	Reason string `json:"reason"`
+}
+
+type maintenanceResponse struct {
+	Maintenance bool       `json:"maintenance"`
+	Reason      string     `json:"reason,omitempty"`
+	Since       *time.Time `json:"since,omitempty"`
+}
+
+// SetMaintenance fails readiness with reason while liveness keeps its status
+func (h *health) SetMaintenance(reason string) {
+	if reason == "" {
+		reason = defaultMaintenanceReason
+	}
+	h.updateLogged(func() func() {
+		s := h.status
+		if !s.maintenance {
+			s.since = time.Now()
+		}
+		s.maintenance, s.reason = true, reason
+		return func() {
+			h.logger.Warnw(`Health: maintenance is set`, `reason`, reason)
+		}
+	})
+}
+
+// ClearMaintenance lets readiness follow its checks again
+func (h *health) ClearMaintenance() {
+	h.updateLogged(func() func() {
+		s := h.status
+		if !s.maintenance {
+			return nil
+		}
+		s.maintenance, s.reason, s.since = false, "", time.Time{}
+		return func() {
+			h.logger.Infow(`Health: maintenance is cleared`)
+		}
+	})
+}
+
+func (h *health) Maintenance() (string, bool) {
+	h.status.mu.RLock()
+	defer h.status.mu.RUnlock()
+	return h.status.reason, h.status.maintenance
+}
+
+func (h *health) MaintenanceEndpoint() string {
+	return h.opt.Maintenance.Endpoint
+}
+
+// MaintenanceHandler returns admin handler of maintenance mode
+func (h *health) MaintenanceHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !h.authorized(r) {
+			h.logger.WarnWithContext(r.Context(), errors.NewWithCode(EcodeUnauthorized, `unauthorized maintenance request from %s`, r.RemoteAddr))
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			var req maintenanceRequest
+			if r.ContentLength != 0 {
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+			}
+			h.SetMaintenance(req.Reason)
+		case http.MethodDelete:
+			h.ClearMaintenance()
+		default:
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		h.status.mu.RLock()
+		resp := maintenanceResponse{
+			Maintenance: h.status.maintenance,
+			Reason:      h.status.reason,
+		}
+		if h.status.maintenance {
+			since := h.status.since
+			resp.Since = &since
+		}
+		h.status.mu.RUnlock()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// authorized compares bearer token in constant time, no token configured denies all
+func (h *health) authorized(r *http.Request) bool {
+	if h.opt.Maintenance.Token == "" {
+		return false
+	}
+	auth := r.Header.Get("Authorization")
+	const prefix = "Bearer "
+	if len(auth) < len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(auth[len(prefix):]), []byte(h.opt.Maintenance.Token)) == 1
+}
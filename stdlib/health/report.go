@@ -0,0 +1,65 @@
+package health
+
+import (
+	"sort"
+	"time"
+)
+
+// Report is probe status within lifecycle state with results of its checks.
+// Readiness report has reason of maintenance mode if any.
+type Report struct {
+	Probe       Probe         `json:"probe"`
+	Status      Status        `json:"status"`
+	State       State         `json:"state"`
+	Maintenance string        `json:"maintenance,omitempty"`
+	Checks      []CheckReport `json:"checks"`
+}
+
+// CheckReport is the last result of a check
+type CheckReport struct {
+	Name           string      `json:"name"`
+	Status         Status      `json:"status"`
+	Criticality    Criticality `json:"criticality"`
+	Error          string      `json:"error,omitempty"`
+	Latency        string      `json:"latency"`
+	LastCheck      time.Time   `json:"last_check"`
+	LastTransition time.Time   `json:"last_transition"`
+	Failures       int         `json:"failures"`
+}
+
+func (h *health) Report(p Probe) Report {
+	s := h.status
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r := Report{
+		Probe:  p,
+		Status: h.probeStatus(p),
+		State:  s.state,
+		Checks: []CheckReport{},
+	}
+	if p == ProbeReadiness {
+		r.Maintenance = s.reason
+	}
+	for _, c := range s.checks {
+		if !c.in(p) {
+			continue
+		}
+		cr := CheckReport{
+			Name:           c.name,
+			Status:         c.status,
+			Criticality:    c.opt.Criticality,
+			Latency:        c.latency.String(),
+			LastCheck:      c.lastCheck,
+			LastTransition: c.lastTransition,
+			Failures:       c.failures,
+		}
+		if c.err != nil {
+			cr.Error = c.err.Error()
+		}
+		r.Checks = append(r.Checks, cr)
+	}
+	sort.Slice(r.Checks, func(i, j int) bool {
+		return r.Checks[i].Name < r.Checks[j].Name
+	})
+	return r
+}
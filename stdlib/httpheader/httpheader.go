@@ -44,6 +44,9 @@ const (
 	CacheNoStore        string = `no-store`
 	CacheMustRevalidate string = `must-revalidate`
 
+	// Retry Header
+	RetryAfter string = `Retry-After`
+
 	// bpm
 	BPMProcessID  string = `x-bpm-process-id`
 	BPMWorkflowID string = `x-bpm-workflow-id`
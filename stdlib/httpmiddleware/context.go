@@ -66,8 +66,9 @@ func (m *httpMiddleware) AppendRequestContext(method string, keyServerRoute stri
 				once:    &sync.Once{},
 				ctx:     ctx,
 				writer:  w,
-				logger:  m.logger,
-				skipLog: skip,
+				logger:   m.logger,
+				skipLog:  skip,
+				scrubber: m.routeScrubber(keyServerRoute),
 			}
 
 			fn(trw, r.WithContext(ctx))
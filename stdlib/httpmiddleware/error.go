@@ -7,11 +7,13 @@ const (
 	EcodeHealth
 	EcodePanic
 	EcodeRequestDump
+	EcodeScrub
 )
 
 const (
 	errPanic  string = `HTTP handler panic: %s full trace :%s`
 	errDump   string = `HTTP request dump error`
 	errHealth string = `Health Middleware Error`
+	errScrub  string = `HTTP log scrubber error`
 )
 
@@ -4,17 +4,68 @@ import (
 	"net/http"
 
 	errors "github.com/mytoko2796/sdk-go/stdlib/error"
+	"github.com/mytoko2796/sdk-go/stdlib/health"
 )
 
+const (
+	// MaintenanceServe keeps serving requests in maintenance mode
+	MaintenanceServe string = `serve`
+	// MaintenanceReject rejects requests in maintenance mode
+	MaintenanceReject string = `reject`
+)
+
+// Healthcheck rejects requests with 503 and Retry-After while health is critical, in
+// maintenance if configured, or when load shedding finds app overloaded. Priority routes
+// are never shed, but they are still rejected while health is critical.
 func (m *httpMiddleware) Healthcheck(fn http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if err := m.healt.IsReadyAndHealthy(); err != nil {
+		reason, maintenance := m.healt.Maintenance()
+		if maintenance && m.healthOpt.Maintenance == MaintenanceReject {
+			m.shed(w, r, ShedMaintenance, "Service is under Maintenance: "+reason)
+			return
+		}
+		if err := m.critical(); err != nil {
 			err = errors.WrapWithCode(err, EcodeHealth, errHealth)
 			m.logger.ErrorWithContext(r.Context(), err)
-			http.Error(w, "Service is Unavailable", http.StatusServiceUnavailable)
+			m.shed(w, r, ShedUnhealthy, "Service is Unavailable")
 			return
 		}
+		if m.shedder == nil || m.shedder.isPriority(requestRoute(r)) {
+			fn(w, r)
+			return
+		}
+		reason, ok := m.shedder.acquire(r.Context())
+		if !ok {
+			// client went away while waiting in queue
+			if reason == "" {
+				return
+			}
+			m.shed(w, r, reason, "Service is Overloaded")
+			return
+		}
+		defer m.shedder.release(r.Context())
 		fn(w, r)
 	}
 }
 
+// critical returns error if app must not serve requests: liveness is down, or readiness
+// checks are down while serving after startup. Probes without results yet, e.g. while app
+// is starting, and readiness failing by lifecycle, e.g. in maintenance or while draining,
+// do not reject.
+func (m *httpMiddleware) critical() error {
+	if m.healt.ProbeStatus(health.ProbeLiveness) == health.StatusDown {
+		return health.ErrNotHealthy
+	}
+	switch m.healt.State() {
+	case health.StateServing, health.StateUnmanaged:
+	default:
+		return nil
+	}
+	if m.healt.IsStarted() != nil {
+		return nil
+	}
+	if m.healt.ChecksStatus(health.ProbeReadiness) == health.StatusDown {
+		return health.ErrNotReady
+	}
+	return nil
+}
@@ -1,10 +1,13 @@
 package httpmiddleware
 
 import (
+	errors "github.com/mytoko2796/sdk-go/stdlib/error"
 	"github.com/mytoko2796/sdk-go/stdlib/health"
 	log "github.com/mytoko2796/sdk-go/stdlib/logger"
 	"github.com/unrolled/secure"
 	"net/http"
+	"sync"
+	"time"
 )
 
 const (
@@ -19,6 +22,7 @@ type HttpMiddleware interface {
 	//	HealthCheck
 	//	RequestDump
 	// 	Secure
+	// It is applied once, later calls do nothing
 	UseDefaultMiddleware()
 	// UseMiddleware append particular middlewares to exisiting middleware collections
 	Use(h ...MiddlewareHandle)
@@ -32,11 +36,43 @@ type HttpMiddleware interface {
 type Options struct {
 	Log      LoggerOptions
 	Security SecurityOptions
+	Health   HealthOptions
+}
+
+// HealthOptions defines Healthcheck middleware
+type HealthOptions struct {
+	// Maintenance is serve or reject, default serve. Serve keeps serving requests still
+	// reaching app in maintenance mode, e.g. over keepalive connections, reject responds 503.
+	Maintenance string
+	// RetryAfter is sent with 503 responses of Healthcheck, rounded up to seconds, default 1s
+	RetryAfter time.Duration
+	// Shedding rejects requests once app is overloaded, disabled by default
+	Shedding SheddingOptions
+}
+
+// SheddingOptions defines load shedding of Healthcheck middleware. Requests above MaxConcurrency
+// wait in queue up to Interval, once queue delay stays above TargetDelay for a whole Interval
+// they wait only up to TargetDelay until the queue drains.
+type SheddingOptions struct {
+	// MaxConcurrency is number of requests served at once, zero disables shedding
+	MaxConcurrency int
+	// MaxQueue is number of requests waiting for a slot, default MaxConcurrency
+	MaxQueue int
+	// TargetDelay is acceptable queue delay, default 5ms
+	TargetDelay time.Duration
+	// Interval is max queue delay while not overloaded, default 100ms
+	Interval time.Duration
+	// PriorityRoutes are never shed e.g. /v1/payment, trailing * matches route prefix e.g. /v1/payment/*
+	PriorityRoutes []string
 }
 
 type LoggerOptions struct {
 	RequestPathBlackList  map[string][]string
 	ResponsePathBlackList map[string][]string
+	// Scrub masks secrets in dumped requests and responses
+	Scrub log.ScrubOptions
+	// ScrubRoutes overrides Scrub for routes e.g. /v1/payment
+	ScrubRoutes map[string]log.ScrubOptions
 }
 
 type SecurityOptions struct {
@@ -67,10 +103,16 @@ type SecurityOptions struct {
 type httpMiddleware struct {
 	logger                log.Logger
 	healt                 health.Health
+	healthOpt             HealthOptions
+	shedder               *shedder
+	retryAfter            string
 	security              *secure.Secure
 	mwares                []MiddlewareHandle
+	defaults              *sync.Once
 	requestPathBlackList  map[string]bool
 	responsePathBlackList map[string]bool
+	scrubber              *log.Scrubber
+	routeScrubbers        map[string]*log.Scrubber
 }
 
 type MiddlewareHandle func(http.HandlerFunc) http.HandlerFunc
@@ -97,9 +139,17 @@ func Init(logger log.Logger, healt health.Health, opt Options) HttpMiddleware {
 		}
 	}
 
+	scrubber, routeScrubbers, err := newScrubbers(opt.Log)
+	if err != nil {
+		logger.Panic(errors.WrapWithCode(err, EcodeScrub, errScrub))
+	}
+
 	return &httpMiddleware{
 		logger: logger,
-		healt:  healt,
+		healt:     healt,
+		healthOpt:  opt.Health,
+		shedder:    newShedder(opt.Health.Shedding),
+		retryAfter: retryAfterSeconds(opt.Health.RetryAfter),
 		security: secure.New(secure.Options{
 			AllowedHosts:            opt.Security.AllowedHosts,
 			HostsProxyHeaders:       opt.Security.HostsProxyHeaders,
@@ -125,18 +175,25 @@ func Init(logger log.Logger, healt health.Health, opt Options) HttpMiddleware {
 			IsDevelopment:           opt.Security.IsDevelopment,
 		}),
 		mwares:                nil,
+		defaults:              &sync.Once{},
 		requestPathBlackList:  requestPathBlackList,
 		responsePathBlackList: responsePathBlackList,
+		scrubber:              scrubber,
+		routeScrubbers:        routeScrubbers,
 	}
 }
 
+// UseDefaultMiddleware appends default middleware once, so muxes sharing m do not wrap
+// handlers twice
 func (m *httpMiddleware) UseDefaultMiddleware() {
-	m.Use(
-		m.CatchPanicAndReport,
-		m.Healthcheck,
-		m.RequestDump,
-		m.Secure,
-	)
+	m.defaults.Do(func() {
+		m.Use(
+			m.CatchPanicAndReport,
+			m.Healthcheck,
+			m.RequestDump,
+			m.Secure,
+		)
+	})
 }
 
 func (m *httpMiddleware) Use(h ...MiddlewareHandle) {
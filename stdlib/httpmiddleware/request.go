@@ -15,10 +15,11 @@ const (
 func (m *httpMiddleware) RequestDump(fn http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var skip bool
+		var keyServerRoute string
 
 		keyServerRouteCtx := r.Context().Value(httpheader.KeyServerRoute)
 		if keyServerRouteCtx != nil {
-			keyServerRoute, _ := keyServerRouteCtx.(string)
+			keyServerRoute, _ = keyServerRouteCtx.(string)
 			if _, ok := m.requestPathBlackList[r.Method+":"+keyServerRoute]; ok {
 				skip = true
 			}
@@ -37,7 +38,7 @@ func (m *httpMiddleware) RequestDump(fn http.HandlerFunc) http.HandlerFunc {
 				http.Error(w, "", http.StatusBadRequest)
 				return
 			}
-			m.logger.InfoWithContext(r.Context(), infoRequestDump, string(dump))
+			m.logger.InfoWithContext(r.Context(), infoRequestDump, m.scrub(keyServerRoute, string(dump)))
 		}
 		fn(w, r)
 	}
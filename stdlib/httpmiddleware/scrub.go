@@ -0,0 +1,47 @@
+package httpmiddleware
+
+import (
+	log "github.com/mytoko2796/sdk-go/stdlib/logger"
+)
+
+// newScrubbers returns default scrubber and scrubbers of routes, nil if scrubbing is disabled
+func newScrubbers(opt LoggerOptions) (*log.Scrubber, map[string]*log.Scrubber, error) {
+	var scrubber *log.Scrubber
+	if opt.Scrub.Enabled {
+		s, err := log.NewScrubber(opt.Scrub)
+		if err != nil {
+			return nil, nil, err
+		}
+		scrubber = s
+	}
+	routes := make(map[string]*log.Scrubber, len(opt.ScrubRoutes))
+	for route, ropt := range opt.ScrubRoutes {
+		if !ropt.Enabled {
+			// disabled route override turns scrubbing off for the route
+			routes[route] = nil
+			continue
+		}
+		s, err := log.NewScrubber(ropt)
+		if err != nil {
+			return nil, nil, err
+		}
+		routes[route] = s
+	}
+	return scrubber, routes, nil
+}
+
+// routeScrubber returns scrubber of route or the default one
+func (m *httpMiddleware) routeScrubber(route string) *log.Scrubber {
+	if s, ok := m.routeScrubbers[route]; ok {
+		return s
+	}
+	return m.scrubber
+}
+
+// scrub masks str with scrubber of route
+func (m *httpMiddleware) scrub(route string, str string) string {
+	if s := m.routeScrubber(route); s != nil {
+		return s.Scrub(str)
+	}
+	return str
+}
@@ -0,0 +1,184 @@
+package httpmiddleware
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mytoko2796/sdk-go/stdlib/httpheader"
+	"github.com/mytoko2796/sdk-go/stdlib/telemetry/stat"
+	"github.com/mytoko2796/sdk-go/stdlib/telemetry/tag"
+	"go.opencensus.io/stats"
+	tags "go.opencensus.io/tag"
+)
+
+const (
+	// ShedUnhealthy is reason of requests rejected as health is critical
+	ShedUnhealthy string = `unhealthy`
+	// ShedMaintenance is reason of requests rejected in maintenance mode
+	ShedMaintenance string = `maintenance`
+	// ShedQueueFull is reason of requests rejected as all slots and queue are taken
+	ShedQueueFull string = `queue_full`
+	// ShedQueueTimeout is reason of requests rejected after waiting too long for a slot
+	ShedQueueTimeout string = `queue_timeout`
+
+	defaultShedTargetDelay = 5 * time.Millisecond
+	defaultShedInterval    = 100 * time.Millisecond
+	defaultRetryAfter      = time.Second
+)
+
+// shedder limits concurrent requests. Requests above the limit wait in queue up to
+// interval, once the shortest queue delay of an interval exceeds target delay queue
+// is standing and requests wait only up to target delay until it drains (CoDel).
+type shedder struct {
+	slots    chan struct{}
+	maxQueue int64
+	queued   int64
+	inFlight int64
+	target   time.Duration
+	interval time.Duration
+	priority map[string]bool
+
+	mu            sync.Mutex
+	intervalStart time.Time
+	minDelay      time.Duration
+	overloaded    bool
+}
+
+func newShedder(opt SheddingOptions) *shedder {
+	if opt.MaxConcurrency <= 0 {
+		return nil
+	}
+	if opt.MaxQueue <= 0 {
+		opt.MaxQueue = opt.MaxConcurrency
+	}
+	if opt.TargetDelay <= 0 {
+		opt.TargetDelay = defaultShedTargetDelay
+	}
+	if opt.Interval <= 0 {
+		opt.Interval = defaultShedInterval
+	}
+	priority := make(map[string]bool)
+	for _, route := range opt.PriorityRoutes {
+		priority[route] = true
+	}
+	return &shedder{
+		slots:         make(chan struct{}, opt.MaxConcurrency),
+		maxQueue:      int64(opt.MaxQueue),
+		target:        opt.TargetDelay,
+		interval:      opt.Interval,
+		priority:      priority,
+		intervalStart: time.Now(),
+		minDelay:      math.MaxInt64,
+	}
+}
+
+// acquire takes a concurrency slot, it returns shed reason if request is rejected
+func (s *shedder) acquire(ctx context.Context) (string, bool) {
+	select {
+	case s.slots <- struct{}{}:
+		s.observe(0)
+		s.enter(ctx)
+		return "", true
+	default:
+	}
+	if atomic.AddInt64(&s.queued, 1) > s.maxQueue {
+		atomic.AddInt64(&s.queued, -1)
+		return ShedQueueFull, false
+	}
+	defer atomic.AddInt64(&s.queued, -1)
+
+	timeout := s.interval
+	if s.isOverloaded() {
+		timeout = s.target
+	}
+	start := time.Now()
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	select {
+	case s.slots <- struct{}{}:
+		delay := time.Since(start)
+		s.observe(delay)
+		stats.Record(ctx, stat.StatShedQueueDelay.M(float64(delay)/float64(time.Millisecond)))
+		s.enter(ctx)
+		return "", true
+	case <-t.C:
+		s.observe(time.Since(start))
+		return ShedQueueTimeout, false
+	case <-ctx.Done():
+		return "", false
+	}
+}
+
+func (s *shedder) enter(ctx context.Context) {
+	stats.Record(ctx, stat.StatShedInFlight.M(atomic.AddInt64(&s.inFlight, 1)))
+}
+
+func (s *shedder) release(ctx context.Context) {
+	stats.Record(ctx, stat.StatShedInFlight.M(atomic.AddInt64(&s.inFlight, -1)))
+	<-s.slots
+}
+
+// observe tracks the shortest queue delay of current interval
+func (s *shedder) observe(delay time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if delay < s.minDelay {
+		s.minDelay = delay
+	}
+	if now := time.Now(); now.Sub(s.intervalStart) >= s.interval {
+		s.overloaded = s.minDelay > s.target
+		s.minDelay = math.MaxInt64
+		s.intervalStart = now
+	}
+}
+
+func (s *shedder) isOverloaded() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.overloaded
+}
+
+// isPriority returns true if route is never shed
+func (s *shedder) isPriority(route string) bool {
+	if s.priority[route] {
+		return true
+	}
+	for r := range s.priority {
+		if strings.HasSuffix(r, "*") && strings.HasPrefix(route, strings.TrimSuffix(r, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// requestRoute returns route carried by AppendRequestContext middleware or request path
+func requestRoute(r *http.Request) string {
+	if route, ok := r.Context().Value(httpheader.KeyServerRoute).(string); ok {
+		return route
+	}
+	return r.URL.Path
+}
+
+// shed rejects request with 503 and Retry-After and records the reason
+func (m *httpMiddleware) shed(w http.ResponseWriter, r *http.Request, reason string, msg string) {
+	stats.RecordWithTags(r.Context(), []tags.Mutator{
+		tags.Upsert(tag.TagKeyServerRoute, requestRoute(r)),
+		tags.Upsert(tag.TagShedReason, reason),
+	}, stat.StatShedCount.M(1))
+	w.Header().Set(httpheader.RetryAfter, m.retryAfter)
+	http.Error(w, msg, http.StatusServiceUnavailable)
+}
+
+// retryAfterSeconds rounds d up to whole seconds, at least one
+func retryAfterSeconds(d time.Duration) string {
+	if d <= 0 {
+		d = defaultRetryAfter
+	}
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
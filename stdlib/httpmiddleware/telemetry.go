@@ -20,6 +20,7 @@ type telemetryResponseWriter struct {
 	logger     log.Logger
 	statusCode int
 	skipLog    bool
+	scrubber   *log.Scrubber
 }
 
 func (r *telemetryResponseWriter) Header() http.Header {
@@ -45,7 +46,11 @@ func (r *telemetryResponseWriter) WriteHeader(statusCode int) {
 
 func (r *telemetryResponseWriter) AfterWrite(data []byte, n int) (int, error) {
 	if !r.skipLog {
-		r.logger.InfoWithContext(r.ctx, infoRespDump, fmt.Sprintf("status=%v payload=%s", r.statusCode, data))
+		payload := string(data)
+		if r.scrubber != nil {
+			payload = r.scrubber.Scrub(payload)
+		}
+		r.logger.InfoWithContext(r.ctx, infoRespDump, fmt.Sprintf("status=%v payload=%s", r.statusCode, payload))
 	}
 	return n, nil
 }
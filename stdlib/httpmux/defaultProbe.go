@@ -1,7 +1,10 @@
 package httpmux
 
 import (
+	"encoding/json"
 	"net/http"
+
+	"github.com/mytoko2796/sdk-go/stdlib/health"
 )
 
 // health converts err returned as http header.code
@@ -19,3 +22,18 @@ func readyHTTPStatus(err error) int {
 	}
 	return http.StatusServiceUnavailable
 }
+
+// startup converts err returned as http header.code
+func startupHTTPStatus(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	return http.StatusServiceUnavailable
+}
+
+// writeReport writes probe report as JSON body
+func writeReport(w http.ResponseWriter, code int, report health.Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(report)
+}
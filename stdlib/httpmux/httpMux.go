@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"text/template"
 
+	"github.com/mytoko2796/sdk-go/stdlib/health"
 	swagger "github.com/swaggo/http-swagger"
 )
 
@@ -59,19 +60,36 @@ func (m *httpMux) registerHTTPProbeHandler() {
 	m.logger.Info(OK, infoMux, fmt.Sprintf("@%s @%s", m.health.ReadyEndpoint(), m.health.HealthEndpoint()))
 	m.handleFunc(true, GET, m.health.ReadyEndpoint(), m.Ready)
 	m.handleFunc(true, GET, m.health.HealthEndpoint(), m.Health)
+	if m.health.StartupEndpoint() != "" {
+		m.logger.Info(OK, infoMux, fmt.Sprintf("@%s", m.health.StartupEndpoint()))
+		m.handleFunc(true, GET, m.health.StartupEndpoint(), m.Startup)
+	}
+	if m.health.MaintenanceEndpoint() != "" {
+		m.logger.Info(OK, infoMux, fmt.Sprintf("@%s", m.health.MaintenanceEndpoint()))
+		// standard mux serves all methods on a path, maintenance handler checks them
+		m.handleFunc(true, PUT, m.health.MaintenanceEndpoint(), m.health.MaintenanceHandler())
+	}
 }
 
 func (m *httpMux) Ready(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(readyHTTPStatus(m.health.IsReady()))
+	writeReport(w, readyHTTPStatus(m.health.IsReady()), m.health.Report(health.ProbeReadiness))
+}
+
+func (m *httpMux) Startup(w http.ResponseWriter, r *http.Request) {
+	writeReport(w, startupHTTPStatus(m.health.IsStarted()), m.health.Report(health.ProbeStartup))
 }
 
 func (m *httpMux) Health(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(healthHTTPStatus(m.health.IsHealthy()))
+	writeReport(w, healthHTTPStatus(m.health.IsHealthy()), m.health.Report(health.ProbeLiveness))
 }
 
 func (m *httpMux) registerHTTPPlatformInfo() {
 	if m.opt.Platform.Enabled {
 		m.handleFunc(true, GET, m.opt.Platform.Path, m.conf.HTTPHandler())
 		m.handleFunc(true, GET, m.opt.Platform.PathRemote, m.remoteConf.HTTPHandler())
+		if m.opt.Platform.PathLogger != "" {
+			// standard mux serves all methods on a path, logger handler checks them
+			m.handleFunc(true, PUT, m.opt.Platform.PathLogger, m.logger.HTTPHandler())
+		}
 	}
 }
@@ -3,6 +3,8 @@ package httpmux
 import (
 	"fmt"
 
+	"github.com/mytoko2796/sdk-go/stdlib/health"
+
 	swagger "github.com/swaggo/http-swagger"
 
 	"net/http"
@@ -34,12 +36,26 @@ func (m *httpRouterMux) registerHTTPProbeHandler() {
 	m.logger.Info(OK, infoMux, fmt.Sprintf("@%s @%s", m.health.ReadyEndpoint(), m.health.HealthEndpoint()))
 	m.handleFunc(true, GET, m.health.ReadyEndpoint(), m.Ready)
 	m.handleFunc(true, GET, m.health.HealthEndpoint(), m.Health)
+	if m.health.StartupEndpoint() != "" {
+		m.logger.Info(OK, infoMux, fmt.Sprintf("@%s", m.health.StartupEndpoint()))
+		m.handleFunc(true, GET, m.health.StartupEndpoint(), m.Startup)
+	}
+	if m.health.MaintenanceEndpoint() != "" {
+		m.logger.Info(OK, infoMux, fmt.Sprintf("@%s", m.health.MaintenanceEndpoint()))
+		m.handleFunc(true, GET, m.health.MaintenanceEndpoint(), m.health.MaintenanceHandler())
+		m.handleFunc(true, PUT, m.health.MaintenanceEndpoint(), m.health.MaintenanceHandler())
+		m.handleFunc(true, DELETE, m.health.MaintenanceEndpoint(), m.health.MaintenanceHandler())
+	}
 }
 
 func (m *httpRouterMux) registerHTTPPlatformInfo() {
 	if m.opt.Platform.Enabled {
 		m.handleFunc(true, GET, m.opt.Platform.Path, m.conf.HTTPHandler())
 		//m.handleFunc(true, GET, m.opt.Platform.PathRemote, m.remoteConf.HTTPHandler())
+		if m.opt.Platform.PathLogger != "" {
+			m.handleFunc(true, GET, m.opt.Platform.PathLogger, m.logger.HTTPHandler())
+			m.handleFunc(true, PUT, m.opt.Platform.PathLogger, m.logger.HTTPHandler())
+		}
 	}
 }
 
@@ -54,12 +70,16 @@ func (m *httpRouterMux) registerHTTPSwagger() {
 	}
 }
 
+func (m *httpRouterMux) Startup(w http.ResponseWriter, r *http.Request) {
+	writeReport(w, startupHTTPStatus(m.health.IsStarted()), m.health.Report(health.ProbeStartup))
+}
+
 func (m *httpRouterMux) Health(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(healthHTTPStatus(m.health.IsHealthy()))
+	writeReport(w, healthHTTPStatus(m.health.IsHealthy()), m.health.Report(health.ProbeLiveness))
 }
 
 func (m *httpRouterMux) Ready(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(readyHTTPStatus(m.health.IsReady()))
+	writeReport(w, readyHTTPStatus(m.health.IsReady()), m.health.Report(health.ProbeReadiness))
 }
 
 func (m *httpRouterMux) swaggerTemplate(w http.ResponseWriter, r *http.Request) {
@@ -9,7 +9,6 @@ import (
 	"github.com/mytoko2796/sdk-go/stdlib/telemetry"
 	"github.com/rs/cors"
 	"net/http"
-	"sync"
 )
 
 type Method string
@@ -45,7 +44,6 @@ const (
 
 
 var (
-	once = &sync.Once{}
 	muxs = map[Mode]string{
 		HTTPMUXSTD: "[HTTPMUXSTD]",
 		HTTPROUTER: "[HTTPROUTER]",
@@ -82,6 +80,8 @@ type PlatformOptions struct {
 	Path string
 	// Path Runtime Config endpoint
 	PathRemote string
+	// PathLogger defines endpoint to read (GET) and change (PUT) log levels at runtime
+	PathLogger string
 }
 
 // SwaggerOptions
@@ -159,73 +159,71 @@ type httpRouterMux struct {
 //	Health will be used to handle healtcheck/readiness check.
 func Init(mode Mode, logger log.Logger, conf config.Conf, remote config.Conf, mw httpmiddleware.HttpMiddleware, tele telemetry.Telemetry, hl health.Health, opt Options) HttpMux {
 	var mux HttpMux
-	once.Do(func() {
-		//Add default middleware
-		// panic recovery
-		// request-id
-		// request Logger
-		// security
-		mw.UseDefaultMiddleware()
-
-		var c *cors.Cors
-		if opt.Cors.Enabled {
-			switch opt.Cors.Mode {
-			case "custom":
-				c = cors.New(cors.Options{
-					AllowedOrigins:     opt.Cors.AllowedOrigins,
-					AllowedMethods:     opt.Cors.AllowedMethods,
-					AllowedHeaders:     opt.Cors.AllowedHeaders,
-					ExposedHeaders:     opt.Cors.ExposedHeaders,
-					MaxAge:             opt.Cors.MaxAge,
-					AllowCredentials:   opt.Cors.AllowCredentials,
-					OptionsPassthrough: opt.Cors.OptionsPassthrough,
-					Debug:              opt.Cors.Debug,
-				})
-			case "allowall":
-				c = cors.AllowAll()
-			case "default":
-				c = cors.Default()
-			default:
-				c = nil
-			}
+	//Add default middleware
+	// panic recovery
+	// request-id
+	// request Logger
+	// security
+	mw.UseDefaultMiddleware()
+
+	var c *cors.Cors
+	if opt.Cors.Enabled {
+		switch opt.Cors.Mode {
+		case "custom":
+			c = cors.New(cors.Options{
+				AllowedOrigins:     opt.Cors.AllowedOrigins,
+				AllowedMethods:     opt.Cors.AllowedMethods,
+				AllowedHeaders:     opt.Cors.AllowedHeaders,
+				ExposedHeaders:     opt.Cors.ExposedHeaders,
+				MaxAge:             opt.Cors.MaxAge,
+				AllowCredentials:   opt.Cors.AllowCredentials,
+				OptionsPassthrough: opt.Cors.OptionsPassthrough,
+				Debug:              opt.Cors.Debug,
+			})
+		case "allowall":
+			c = cors.AllowAll()
+		case "default":
+			c = cors.Default()
+		default:
+			c = nil
 		}
+	}
 
-		switch mode {
-		case HTTPMUXSTD:
-			httpMuxStd := &httpMux{
-				logger:     logger,
-				mw:         mw,
-				mux:        http.NewServeMux(),
-				tele:       tele,
-				health:     hl,
-				opt:        opt,
-				conf:       conf,
-				remoteConf: remote,
-				cors:       c,
-			}
-			httpMuxStd.registerHTTPSwagger()
-			httpMuxStd.registerHTTPProbeHandler()
-			httpMuxStd.registerHTTPPlatformInfo()
-			mux = httpMuxStd
-
-		case HTTPROUTER:
-			httpRouterMux := &httpRouterMux{
-				logger:     logger,
-				mw:         mw,
-				mux:        pat.New(),
-				tele:       tele,
-				health:     hl,
-				opt:        opt,
-				conf:       conf,
-				remoteConf: remote,
-				cors:       c,
-			}
-			httpRouterMux.registerHTTPSwagger()
-			httpRouterMux.registerHTTPProbeHandler()
-			httpRouterMux.registerHTTPPlatformInfo()
-			mux = httpRouterMux
+	switch mode {
+	case HTTPMUXSTD:
+		httpMuxStd := &httpMux{
+			logger:     logger,
+			mw:         mw,
+			mux:        http.NewServeMux(),
+			tele:       tele,
+			health:     hl,
+			opt:        opt,
+			conf:       conf,
+			remoteConf: remote,
+			cors:       c,
+		}
+		httpMuxStd.registerHTTPSwagger()
+		httpMuxStd.registerHTTPProbeHandler()
+		httpMuxStd.registerHTTPPlatformInfo()
+		mux = httpMuxStd
+
+	case HTTPROUTER:
+		httpRouterMux := &httpRouterMux{
+			logger:     logger,
+			mw:         mw,
+			mux:        pat.New(),
+			tele:       tele,
+			health:     hl,
+			opt:        opt,
+			conf:       conf,
+			remoteConf: remote,
+			cors:       c,
 		}
-		logger.Info(OK, infoMux, muxs[mode])
-	})
+		httpRouterMux.registerHTTPSwagger()
+		httpRouterMux.registerHTTPProbeHandler()
+		httpRouterMux.registerHTTPPlatformInfo()
+		mux = httpRouterMux
+	}
+	logger.Info(OK, infoMux, muxs[mode])
 	return mux
 }
\ No newline at end of file
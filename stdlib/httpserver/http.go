@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	errors "github.com/mytoko2796/sdk-go/stdlib/error"
+	"github.com/mytoko2796/sdk-go/stdlib/health"
 )
 
 func (h *httpServer) GetTLSKey() string {
@@ -23,6 +24,7 @@ func (h *httpServer) Serve(mode int, ln net.Listener) {
 	}
 	h.logger.Info(OK, infoServe, fmt.Sprintf("%s @%s", server[mode], ln.Addr().String()))
 	if h.servers[mode] != nil {
+		h.health.SetState(health.StateServing)
 		if mode == HTTPS && h.opt.TLSEnabled && h.opt.TLSCertFile != "" && h.opt.TLSKeyFile != "" {
 			if err := h.servers[mode].ServeTLS(ln, h.opt.TLSCertFile, h.opt.TLSKeyFile); err != http.ErrServerClosed {
 				err = errors.WrapWithCode(err, EcodeListenerFailed, errServe, FAILED, server[mode])
@@ -3,12 +3,12 @@ package httpserver
 import (
 	"crypto/tls"
 	"fmt"
+	"github.com/mytoko2796/sdk-go/stdlib/health"
 	log "github.com/mytoko2796/sdk-go/stdlib/logger"
 	"github.com/mytoko2796/sdk-go/stdlib/telemetry"
 	"github.com/mytoko2796/sdk-go/stdlib/httpmux"
 	l "log"
 	"net"
-	"sync"
 	"net/http"
 	"time"
 	"context"
@@ -28,7 +28,6 @@ const (
 )
 
 var (
-	once   = &sync.Once{}
 	server = []string{
 		HTTP:  "[HTTP]",
 		HTTPS: "[HTTPS]",
@@ -37,7 +36,8 @@ var (
 
 type HTTPServer interface {
 	Serve(mode int, ln net.Listener)
-	Shutdown()
+	// Shutdown stops accepting connections and waits for in-flight requests until ctx is done
+	Shutdown(ctx context.Context) error
 	GetServers() []*Server
 	GetTLSCert() string
 	GetTLSKey() string
@@ -65,28 +65,28 @@ type Options struct {
 
 type httpServer struct {
 	logger  log.Logger
+	health  health.Health
 	servers []*http.Server
 	opt     Options
 }
 
-func Init(logger log.Logger, tele telemetry.Telemetry, mux httpmux.HttpMux, opt Options) HTTPServer {
-	var (
-		h *httpServer
-	)
-	once.Do(func() {
-		//init http server
-		h = &httpServer{
-			logger:  logger,
-			opt:     opt,
-			servers: nil,
-		}
+// Init returns HTTPServer driving health lifecycle. It is set to starting on Init,
+// to serving once a server starts serving and to draining as soon as Shutdown is called.
+func Init(logger log.Logger, tele telemetry.Telemetry, mux httpmux.HttpMux, hl health.Health, opt Options) HTTPServer {
+	hl.SetState(health.StateStarting)
+	//init http server
+	h := &httpServer{
+		logger:  logger,
+		health:  hl,
+		opt:     opt,
+		servers: nil,
+	}
 
-		//Intercept Handler with telemetry handle
-		handler := tele.WrapMuxHandler(mux.Handler())
+	//Intercept Handler with telemetry handle
+	handler := tele.WrapMuxHandler(mux.Handler())
 
-		//Init HTTP Server with Handler
-		h.InitHTTPServer(handler)
-	})
+	//Init HTTP Server with Handler
+	h.InitHTTPServer(handler)
 	return h
 }
 
@@ -105,13 +105,22 @@ func (h *httpServer) GetServers() []*Server {
 	return servers
 }
 
-func (h *httpServer) Shutdown() {
+// Shutdown shuts down all servers and returns the first error. Connections still
+// active once ctx is done are closed.
+func (h *httpServer) Shutdown(ctx context.Context) error {
+	h.health.SetState(health.StateDraining)
+	var result error
 	for _, s := range h.servers {
-		if err := s.Shutdown(context.Background()); err != nil {
-			err = errors.WrapWithCode(err, EcodeAppShutdownFailed, errServe, FAILED, s.Addr)
-			s.ErrorLog.Fatal(err)
+		if err := s.Shutdown(ctx); err != nil {
+			s.Close()
+			err = errors.WrapWithCode(err, EcodeAppShutdownFailed, `%s Server @%s`, FAILED, s.Addr)
+			h.logger.Error(err)
+			if result == nil {
+				result = err
+			}
 		}
 	}
+	return result
 }
 
 
@@ -0,0 +1,248 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	lr "github.com/sirupsen/logrus"
+	"go.opencensus.io/stats"
+	tags "go.opencensus.io/tag"
+
+	"github.com/mytoko2796/sdk-go/stdlib/telemetry/stat"
+	"github.com/mytoko2796/sdk-go/stdlib/telemetry/tag"
+)
+
+const (
+	// OverflowBlock blocks callers until buffer has room
+	OverflowBlock string = `block`
+	// OverflowDropNewest drops incoming entries while buffer is full
+	OverflowDropNewest string = `drop_newest`
+	// OverflowDropOldest evicts the oldest buffered entry to make room
+	OverflowDropOldest string = `drop_oldest`
+	// OverflowDropBelowLevel drops incoming entries less severe than DropLevel and blocks for the rest
+	OverflowDropBelowLevel string = `drop_below_level`
+
+	defaultAsyncBufferSize   = 4096
+	defaultAsyncFlushTimeout = 5 * time.Second
+)
+
+// AsyncOptions defines asynchronous output. Entries are formatted by the caller
+// and written by a background goroutine from a bounded buffer.
+type AsyncOptions struct {
+	Enabled bool
+	// BufferSize is the number of buffered entries, default 4096
+	BufferSize int
+	// Overflow defines what happens when buffer is full: block, drop_newest, drop_oldest
+	// or drop_below_level, default block
+	Overflow string
+	// DropLevel is the least severe level kept by drop_below_level policy, default warn
+	DropLevel string
+	// FlushTimeout bounds flushing on Stop, Fatal and Panic, default 5s
+	FlushTimeout time.Duration
+}
+
+type asyncEntry struct {
+	level lr.Level
+	data  []byte
+}
+
+// writeFunc writes formatted entry of level
+type writeFunc func(level lr.Level, p []byte) error
+
+// writerFunc returns writeFunc ignoring level
+func writerFunc(w io.Writer) writeFunc {
+	return func(_ lr.Level, p []byte) error {
+		_, err := w.Write(p)
+		return err
+	}
+}
+
+// levelWriter strips level prefixed by levelFormatter and writes entry synchronously
+type levelWriter writeFunc
+
+func (w levelWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if err := w(lr.Level(p[0]), p[1:]); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// asyncWriter writes buffered entries to out in a background goroutine
+type asyncWriter struct {
+	mu       *sync.Mutex
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+	idle     *sync.Cond
+	out      writeFunc
+	opt      AsyncOptions
+	drop     lr.Level
+	buf      []asyncEntry
+	head     int
+	count    int
+	writing  bool
+	closed   bool
+	done     chan struct{}
+}
+
+func newAsyncWriter(out writeFunc, opt AsyncOptions) *asyncWriter {
+	if opt.BufferSize <= 0 {
+		opt.BufferSize = defaultAsyncBufferSize
+	}
+	if opt.Overflow == "" {
+		opt.Overflow = OverflowBlock
+	}
+	if opt.FlushTimeout <= 0 {
+		opt.FlushTimeout = defaultAsyncFlushTimeout
+	}
+	drop, err := lr.ParseLevel(opt.DropLevel)
+	if err != nil {
+		drop = lr.WarnLevel
+	}
+	mu := &sync.Mutex{}
+	w := &asyncWriter{
+		mu:       mu,
+		notEmpty: sync.NewCond(mu),
+		notFull:  sync.NewCond(mu),
+		idle:     sync.NewCond(mu),
+		out:      out,
+		opt:      opt,
+		drop:     drop,
+		buf:      make([]asyncEntry, opt.BufferSize),
+		done:     make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Write accepts entries formatted by levelFormatter, the first byte holds entry level
+func (w *asyncWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	w.writeLevel(lr.Level(p[0]), p[1:])
+	return len(p), nil
+}
+
+// writeLevel enqueues a copy of p according to overflow policy
+func (w *asyncWriter) writeLevel(level lr.Level, p []byte) {
+	data := make([]byte, len(p))
+	copy(data, p)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for w.count == len(w.buf) && !w.closed {
+		switch {
+		case w.opt.Overflow == OverflowDropNewest,
+			w.opt.Overflow == OverflowDropBelowLevel && level > w.drop:
+			recordDropped(level)
+			return
+		case w.opt.Overflow == OverflowDropOldest:
+			recordDropped(w.buf[w.head].level)
+			w.buf[w.head] = asyncEntry{}
+			w.head = (w.head + 1) % len(w.buf)
+			w.count--
+		default:
+			w.notFull.Wait()
+		}
+	}
+	if w.closed {
+		// background writer is gone, write synchronously so nothing is lost at exit
+		w.out(level, data)
+		return
+	}
+	w.buf[(w.head+w.count)%len(w.buf)] = asyncEntry{level: level, data: data}
+	w.count++
+	w.notEmpty.Signal()
+}
+
+func (w *asyncWriter) run() {
+	defer close(w.done)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for {
+		for w.count == 0 && !w.closed {
+			w.idle.Broadcast()
+			w.notEmpty.Wait()
+		}
+		if w.count == 0 && w.closed {
+			w.idle.Broadcast()
+			return
+		}
+		e := w.buf[w.head]
+		w.buf[w.head] = asyncEntry{}
+		w.head = (w.head + 1) % len(w.buf)
+		w.count--
+		w.writing = true
+		w.notFull.Signal()
+
+		w.mu.Unlock()
+		if err := w.out(e.level, e.data); err != nil {
+			io.WriteString(os.Stderr, err.Error()+"\n")
+		}
+		w.mu.Lock()
+		w.writing = false
+	}
+}
+
+// Flush waits until buffered entries are written or FlushTimeout passes
+func (w *asyncWriter) Flush() {
+	timer := time.AfterFunc(w.opt.FlushTimeout, func() {
+		w.mu.Lock()
+		w.idle.Broadcast()
+		w.mu.Unlock()
+	})
+	defer timer.Stop()
+	deadline := time.Now().Add(w.opt.FlushTimeout)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for (w.count > 0 || w.writing) && time.Now().Before(deadline) {
+		w.idle.Wait()
+	}
+}
+
+// Close flushes buffered entries and stops background writer
+func (w *asyncWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.notEmpty.Broadcast()
+	w.notFull.Broadcast()
+	w.mu.Unlock()
+
+	select {
+	case <-w.done:
+	case <-time.After(w.opt.FlushTimeout):
+	}
+	return nil
+}
+
+// recordDropped counts entries dropped by full buffers
+func recordDropped(level lr.Level) {
+	stats.RecordWithTags(context.Background(),
+		[]tags.Mutator{tags.Upsert(tag.TagLoggerLogLevel, level.String())},
+		stat.StatLoggerDropped.M(1))
+}
+
+// levelFormatter prefixes formatted entries with their level for asyncWriter and levelWriter,
+// since logrus formats entries outside of its lock the level can not be passed otherwise
+type levelFormatter struct {
+	lr.Formatter
+}
+
+func (f *levelFormatter) Format(e *lr.Entry) ([]byte, error) {
+	data, err := f.Formatter.Format(e)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{byte(e.Level)}, data...), nil
+}
@@ -1,5 +1,68 @@
 package logger
 
+import (
+	"fmt"
+	"time"
+
+	lr "github.com/sirupsen/logrus"
+)
+
+const (
+	// badKey is used for values passed without key in key-value methods
+	badKey string = `!BADKEY`
+)
+
+// Field is a key-value pair emitted as a separate field by structured formatters
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// String returns string field
+func String(key string, val string) Field {
+	return Field{Key: key, Value: val}
+}
+
+// Int returns int field
+func Int(key string, val int) Field {
+	return Field{Key: key, Value: val}
+}
+
+// Int64 returns int64 field
+func Int64(key string, val int64) Field {
+	return Field{Key: key, Value: val}
+}
+
+// Float64 returns float64 field
+func Float64(key string, val float64) Field {
+	return Field{Key: key, Value: val}
+}
+
+// Bool returns bool field
+func Bool(key string, val bool) Field {
+	return Field{Key: key, Value: val}
+}
+
+// Duration returns duration field formatted as string e.g. 1.5s
+func Duration(key string, val time.Duration) Field {
+	return Field{Key: key, Value: val.String()}
+}
+
+// Time returns time field
+func Time(key string, val time.Time) Field {
+	return Field{Key: key, Value: val}
+}
+
+// Err returns error field under logrus error key
+func Err(err error) Field {
+	return Field{Key: lr.ErrorKey, Value: err}
+}
+
+// Any returns field of arbitrary value
+func Any(key string, val interface{}) Field {
+	return Field{Key: key, Value: val}
+}
+
 func (l *logrusImpl) setDefaultFields() {
 	l.mu.RLock()
 	for k, v := range l.opt.DefaultFields {
@@ -8,3 +71,43 @@ func (l *logrusImpl) setDefaultFields() {
 	l.mu.RUnlock()
 }
 
+// With returns child logger sharing output and options with every field attached
+func (l *logrusImpl) With(fields ...Field) Logger {
+	return &logrusImpl{
+		core: l.core,
+		log:  l.log.WithFields(toFields(fields...)),
+		name: l.name,
+	}
+}
+
+func toFields(fields ...Field) lr.Fields {
+	out := make(lr.Fields, len(fields))
+	for _, f := range fields {
+		out[f.Key] = f.Value
+	}
+	return out
+}
+
+// parseKeyValues converts alternating key-value pairs into fields.
+// Field values are accepted as is, dangling or non string keys are kept under badKey.
+func parseKeyValues(kv []interface{}) lr.Fields {
+	out := make(lr.Fields, len(kv)/2)
+	for i := 0; i < len(kv); {
+		switch k := kv[i].(type) {
+		case Field:
+			out[k.Key] = k.Value
+			i++
+		case string:
+			if i+1 == len(kv) {
+				out[badKey] = k
+				return out
+			}
+			out[k] = kv[i+1]
+			i += 2
+		default:
+			out[fmt.Sprintf("%s%d", badKey, i)] = k
+			i++
+		}
+	}
+	return out
+}
@@ -2,33 +2,112 @@ package logger
 
 import (
 	"fmt"
+	"time"
 	lr "github.com/sirupsen/logrus"
-	"github.com/mytoko2796/sdk-go/stdlib/error"
+	errors "github.com/mytoko2796/sdk-go/stdlib/error"
 )
 
 const (
 	FormatJSON string = "json"
 	FormatText string = "text"
+	// FormatECS writes Elastic Common Schema JSON
+	FormatECS string = "ecs"
+	// FormatGCP writes Google Cloud Logging structured JSON
+	FormatGCP string = "gcp"
+	// FormatLogfmt writes key=value pairs
+	FormatLogfmt string = "logfmt"
+	// FormatConsole writes colored human friendly lines for development
+	FormatConsole string = "console"
 
 	formatJSON          string = "[JSON]"
 	formatText          string = "[TEXT]"
+	formatECS           string = "[ECS]"
+	formatGCP           string = "[GCP]"
+	formatLogfmt        string = "[LOGFMT]"
+	formatConsole       string = "[CONSOLE]"
 	formatUnknownstring        = "[UNKNOWN LOG FORMAT]"
 )
 
 var (
+	formats = map[string]string{
+		FormatJSON:    formatJSON,
+		FormatText:    formatText,
+		FormatECS:     formatECS,
+		FormatGCP:     formatGCP,
+		FormatLogfmt:  formatLogfmt,
+		FormatConsole: formatConsole,
+	}
+
 	errUnknownFormat = fmt.Errorf(`Unknown log format`)
-	ErrUnknownFormat = error.Wrapf(errUnknownFormat, errLogger, FAILED)
+	ErrUnknownFormat = errors.Wrapf(errUnknownFormat, errLogger, FAILED)
 )
 
+// FormatterOptions customizes formatter output
+type FormatterOptions struct {
+	// TimestampFormat is time layout, default RFC3339 for text, json and logfmt,
+	// RFC3339Nano for ecs and gcp and 15:04:05.000 for console
+	TimestampFormat string
+	// FieldMap renames fields, e.g. msg to message. Keys are field names of the
+	// format, including time, level, msg, caller and func of text, json, logfmt and console.
+	FieldMap map[string]string
+	// ReportCaller adds file:line and function of the log call
+	ReportCaller bool
+	// DisableColors disables colors of console format, e.g. when output is not a terminal
+	DisableColors bool
+}
+
+// key returns field name after renaming
+func (o FormatterOptions) key(k string) string {
+	if v, ok := o.FieldMap[k]; ok {
+		return v
+	}
+	return k
+}
+
+func (o FormatterOptions) timestamp(t time.Time, layout string) string {
+	if o.TimestampFormat != "" {
+		layout = o.TimestampFormat
+	}
+	return t.Format(layout)
+}
+
 func (l *logrusImpl) convertAndSetFormatter() {
-	switch l.opt.Formatter {
+	f, err := newFormatter(l.opt.Formatter, l.opt.FormatterOptions)
+	if err != nil {
+		l.log.Panic(err)
+	}
+	l.setFormatter(f)
+	l.log.Info(OK, infoLogger, formats[l.opt.Formatter])
+}
+
+// newFormatter returns formatter by name, it is shared by logger output and sinks
+func newFormatter(name string, opt FormatterOptions) (lr.Formatter, error) {
+	switch name {
 	case FormatText:
-		l.logger.SetFormatter(&lr.TextFormatter{})
-		l.log.Info(OK, infoLogger, formatText)
+		return newDataFormatter(&lr.TextFormatter{
+			FullTimestamp:   opt.TimestampFormat != "",
+			TimestampFormat: opt.TimestampFormat,
+			FieldMap:        opt.logrusFieldMap(),
+		}, opt), nil
 	case FormatJSON:
-		l.logger.SetFormatter(&lr.JSONFormatter{})
-		l.log.Info(OK, infoLogger, formatJSON)
+		return newDataFormatter(&lr.JSONFormatter{
+			TimestampFormat: opt.TimestampFormat,
+			FieldMap:        opt.logrusFieldMap(),
+		}, opt), nil
+	case FormatECS:
+		return &ecsFormatter{opt: opt}, nil
+	case FormatGCP:
+		return &gcpFormatter{opt: opt}, nil
+	case FormatLogfmt:
+		return &logfmtFormatter{opt: opt}, nil
+	case FormatConsole:
+		return &consoleFormatter{opt: opt}, nil
 	default:
-		l.log.Panic(ErrUnknownFormat)
+		return nil, ErrUnknownFormat
 	}
 }
+
+// setFormatter sets logrus formatter, output writers need entries prefixed by their level
+func (l *logrusImpl) setFormatter(f lr.Formatter) {
+	l.logger.SetFormatter(&levelFormatter{Formatter: f})
+}
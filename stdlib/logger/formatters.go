@@ -0,0 +1,313 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	lr "github.com/sirupsen/logrus"
+)
+
+const (
+	fieldTime   string = `time`
+	fieldLevel  string = `level`
+	fieldMsg    string = `msg`
+	fieldCaller string = `caller`
+	fieldFunc   string = `func`
+
+	ecsVersion     string = `1.6.0`
+	consoleLayout  string = `15:04:05.000`
+	logrusPackage  string = `github.com/sirupsen/logrus`
+	maxCallerDepth        = 32
+)
+
+var (
+	// loggerPackage frames are skipped together with logrus frames when looking up the caller
+	loggerPackage = reflect.TypeOf(logrusImpl{}).PkgPath()
+
+	// gcpSeverity maps logrus level to Cloud Logging LogSeverity
+	gcpSeverity = map[lr.Level]string{
+		lr.PanicLevel: `ALERT`,
+		lr.FatalLevel: `CRITICAL`,
+		lr.ErrorLevel: `ERROR`,
+		lr.WarnLevel:  `WARNING`,
+		lr.InfoLevel:  `INFO`,
+		lr.DebugLevel: `DEBUG`,
+		lr.TraceLevel: `DEBUG`,
+	}
+
+	consoleLevels = map[lr.Level]string{
+		lr.PanicLevel: `PNC`,
+		lr.FatalLevel: `FTL`,
+		lr.ErrorLevel: `ERR`,
+		lr.WarnLevel:  `WRN`,
+		lr.InfoLevel:  `INF`,
+		lr.DebugLevel: `DBG`,
+		lr.TraceLevel: `TRC`,
+	}
+
+	// consoleColors are ANSI colors of levels
+	consoleColors = map[lr.Level]int{
+		lr.PanicLevel: 35,
+		lr.FatalLevel: 35,
+		lr.ErrorLevel: 31,
+		lr.WarnLevel:  33,
+		lr.InfoLevel:  32,
+		lr.DebugLevel: 90,
+		lr.TraceLevel: 90,
+	}
+)
+
+// logrusFieldMap returns renames of logrus built-in keys
+func (o FormatterOptions) logrusFieldMap() lr.FieldMap {
+	fm := lr.FieldMap{}
+	if v, ok := o.FieldMap[fieldTime]; ok {
+		fm[lr.FieldKeyTime] = v
+	}
+	if v, ok := o.FieldMap[fieldLevel]; ok {
+		fm[lr.FieldKeyLevel] = v
+	}
+	if v, ok := o.FieldMap[fieldMsg]; ok {
+		fm[lr.FieldKeyMsg] = v
+	}
+	return fm
+}
+
+// callerFrame returns the first frame outside of logger and logrus packages.
+// Formatters run on the goroutine of the log call, so the frame is the log call site.
+func callerFrame() *runtime.Frame {
+	pcs := make([]uintptr, maxCallerDepth)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		f, more := frames.Next()
+		if pkg := funcPackage(f.Function); pkg != loggerPackage && pkg != logrusPackage {
+			return &f
+		}
+		if !more {
+			return nil
+		}
+	}
+}
+
+// funcPackage returns package path of fully qualified function name
+func funcPackage(fn string) string {
+	slash := strings.LastIndex(fn, "/")
+	if i := strings.Index(fn[slash+1:], "."); i >= 0 {
+		return fn[:slash+1+i]
+	}
+	return fn
+}
+
+// callerFile returns file with its directory, e.g. handler/user.go
+func callerFile(f *runtime.Frame) string {
+	return filepath.Join(filepath.Base(filepath.Dir(f.File)), filepath.Base(f.File))
+}
+
+// fieldValue returns value encodable as JSON, errors are encoded by their message
+func fieldValue(v interface{}) interface{} {
+	if err, ok := v.(error); ok {
+		return err.Error()
+	}
+	return v
+}
+
+// sortedKeys returns field names in order
+func sortedKeys(data lr.Fields) []string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// marshalLine encodes v as a JSON line without escaping HTML
+func marshalLine(v interface{}) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, fmt.Errorf(`failed to marshal fields to JSON, %v`, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// dataFormatter renames entry fields and adds caller fields before logrus formatters
+type dataFormatter struct {
+	lr.Formatter
+	opt FormatterOptions
+}
+
+func newDataFormatter(f lr.Formatter, opt FormatterOptions) lr.Formatter {
+	if !opt.ReportCaller && len(opt.FieldMap) == 0 {
+		return f
+	}
+	return &dataFormatter{Formatter: f, opt: opt}
+}
+
+// Format formats a copy of the entry, the entry is shared by output and sinks
+func (f *dataFormatter) Format(e *lr.Entry) ([]byte, error) {
+	entry := *e
+	entry.Data = make(lr.Fields, len(e.Data)+2)
+	for k, v := range e.Data {
+		entry.Data[f.opt.key(k)] = v
+	}
+	if f.opt.ReportCaller {
+		if c := callerFrame(); c != nil {
+			entry.Data[f.opt.key(fieldCaller)] = callerFile(c) + ":" + strconv.Itoa(c.Line)
+			entry.Data[f.opt.key(fieldFunc)] = c.Function
+		}
+	}
+	return f.Formatter.Format(&entry)
+}
+
+// ecsFormatter writes Elastic Common Schema JSON with dotted field names
+type ecsFormatter struct {
+	opt FormatterOptions
+}
+
+func (f *ecsFormatter) Format(e *lr.Entry) ([]byte, error) {
+	data := make(map[string]interface{}, len(e.Data)+7)
+	for k, v := range e.Data {
+		if k == lr.ErrorKey {
+			k = `error.message`
+		}
+		data[f.opt.key(k)] = fieldValue(v)
+	}
+	data[f.opt.key(`@timestamp`)] = f.opt.timestamp(e.Time, time.RFC3339Nano)
+	data[f.opt.key(`log.level`)] = levelName(e.Level)
+	data[f.opt.key(`message`)] = e.Message
+	data[`ecs.version`] = ecsVersion
+	if f.opt.ReportCaller {
+		if c := callerFrame(); c != nil {
+			data[f.opt.key(`log.origin.file.name`)] = callerFile(c)
+			data[f.opt.key(`log.origin.file.line`)] = c.Line
+			data[f.opt.key(`log.origin.function`)] = c.Function
+		}
+	}
+	return marshalLine(data)
+}
+
+// gcpFormatter writes structured JSON recognized by Cloud Logging agents. Trace
+// correlation fields are added by TraceFormatStackdriver.
+type gcpFormatter struct {
+	opt FormatterOptions
+}
+
+type gcpSourceLocation struct {
+	File     string `json:"file"`
+	Line     string `json:"line"`
+	Function string `json:"function"`
+}
+
+func (f *gcpFormatter) Format(e *lr.Entry) ([]byte, error) {
+	data := make(map[string]interface{}, len(e.Data)+4)
+	for k, v := range e.Data {
+		data[f.opt.key(k)] = fieldValue(v)
+	}
+	data[f.opt.key(`time`)] = f.opt.timestamp(e.Time, time.RFC3339Nano)
+	data[f.opt.key(`severity`)] = gcpSeverity[e.Level]
+	data[f.opt.key(`message`)] = e.Message
+	if f.opt.ReportCaller {
+		if c := callerFrame(); c != nil {
+			data[f.opt.key(`logging.googleapis.com/sourceLocation`)] = gcpSourceLocation{
+				File:     c.File,
+				Line:     strconv.Itoa(c.Line),
+				Function: c.Function,
+			}
+		}
+	}
+	return marshalLine(data)
+}
+
+// logfmtFormatter writes time, level and msg followed by sorted fields as key=value pairs
+type logfmtFormatter struct {
+	opt FormatterOptions
+}
+
+func (f *logfmtFormatter) Format(e *lr.Entry) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	writeLogfmt(buf, f.opt.key(fieldTime), f.opt.timestamp(e.Time, time.RFC3339))
+	writeLogfmt(buf, f.opt.key(fieldLevel), levelName(e.Level))
+	writeLogfmt(buf, f.opt.key(fieldMsg), e.Message)
+	for _, k := range sortedKeys(e.Data) {
+		writeLogfmt(buf, f.opt.key(k), e.Data[k])
+	}
+	if f.opt.ReportCaller {
+		if c := callerFrame(); c != nil {
+			writeLogfmt(buf, f.opt.key(fieldCaller), callerFile(c)+":"+strconv.Itoa(c.Line))
+			writeLogfmt(buf, f.opt.key(fieldFunc), c.Function)
+		}
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+func writeLogfmt(buf *bytes.Buffer, key string, v interface{}) {
+	if buf.Len() > 0 {
+		buf.WriteByte(' ')
+	}
+	buf.WriteString(key)
+	buf.WriteByte('=')
+	buf.WriteString(logfmtValue(v))
+}
+
+// logfmtValue quotes values containing spaces, quotes, equal signs or control characters
+func logfmtValue(v interface{}) string {
+	s := fmt.Sprint(fieldValue(v))
+	if s == "" {
+		return `""`
+	}
+	for _, r := range s {
+		if r <= ' ' || r == '=' || r == '"' || r == 0x7f {
+			return strconv.Quote(s)
+		}
+	}
+	return s
+}
+
+// consoleFormatter writes colored lines for humans, it is not meant to be parsed
+type consoleFormatter struct {
+	opt FormatterOptions
+}
+
+func (f *consoleFormatter) Format(e *lr.Entry) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	f.colored(buf, 90, f.opt.timestamp(e.Time, consoleLayout))
+	buf.WriteByte(' ')
+	f.colored(buf, consoleColors[e.Level], consoleLevels[e.Level])
+	buf.WriteByte(' ')
+	buf.WriteString(e.Message)
+	for _, k := range sortedKeys(e.Data) {
+		f.field(buf, f.opt.key(k), logfmtValue(e.Data[k]))
+	}
+	if f.opt.ReportCaller {
+		if c := callerFrame(); c != nil {
+			f.field(buf, f.opt.key(fieldCaller), callerFile(c)+":"+strconv.Itoa(c.Line))
+		}
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+func (f *consoleFormatter) field(buf *bytes.Buffer, key, val string) {
+	buf.WriteByte(' ')
+	f.colored(buf, 36, key+"=")
+	buf.WriteString(val)
+}
+
+func (f *consoleFormatter) colored(buf *bytes.Buffer, color int, s string) {
+	if f.opt.DisableColors {
+		buf.WriteString(s)
+		return
+	}
+	fmt.Fprintf(buf, "\x1b[%dm%s\x1b[0m", color, s)
+}
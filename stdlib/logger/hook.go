@@ -0,0 +1,34 @@
+package logger
+
+import (
+	"context"
+
+	lr "github.com/sirupsen/logrus"
+	"go.opencensus.io/stats"
+	tags "go.opencensus.io/tag"
+
+	"github.com/mytoko2796/sdk-go/stdlib/telemetry/stat"
+	"github.com/mytoko2796/sdk-go/stdlib/telemetry/tag"
+)
+
+// countHook records number of written entries by level and named logger,
+// entries suppressed by sampling are counted by logLevel
+type countHook struct{}
+
+func (h *countHook) Levels() []lr.Level {
+	return lr.AllLevels
+}
+
+func (h *countHook) Fire(e *lr.Entry) error {
+	name, _ := e.Data[fieldLogger].(string)
+	return recordCount(e.Level, name)
+}
+
+// recordCount counts logged entry of level, name is empty for root logger
+func recordCount(level lr.Level, name string) error {
+	mutators := []tags.Mutator{tags.Upsert(tag.TagLoggerLogLevel, level.String())}
+	if name != "" {
+		mutators = append(mutators, tags.Upsert(tag.TagLoggerComponent, name))
+	}
+	return stats.RecordWithTags(context.Background(), mutators, stat.StatLoggerLogCount.M(1))
+}
@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HTTPOptions defines authentication of HTTPHandler
+type HTTPOptions struct {
+	// Token is required as bearer token, handler rejects every request without token
+	Token string
+}
+
+// levelRequest changes level of a logger. Empty Logger changes global level,
+// empty Level resets named logger to its parent level.
+type levelRequest struct {
+	Logger string `json:"logger"`
+	Level  string `json:"level"`
+	// Revert restores previous level after duration e.g. 10m
+	Revert string `json:"revert"`
+}
+
+type levelResponse struct {
+	Level   string            `json:"level"`
+	Loggers map[string]string `json:"loggers"`
+}
+
+// HTTPHandler returns authenticated http.HandlerFunc to read levels with GET and change them with PUT, e.g.
+//
+//	{"logger": "sql", "level": "debug", "revert": "10m"}
+func (l *logrusImpl) HTTPHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !l.authorized(r) {
+			l.log.WithContext(r.Context()).Warn(fmt.Sprintf("unauthorized log level request from %s", r.RemoteAddr))
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			if err := l.putLevel(r); err != nil {
+				l.log.WithContext(r.Context()).Error(err)
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		global, named := l.levels.snapshot()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(levelResponse{Level: global, Loggers: named})
+	}
+}
+
+// authorized compares bearer token in constant time, no token configured denies all
+func (l *logrusImpl) authorized(r *http.Request) bool {
+	l.mu.RLock()
+	token := l.opt.HTTP.Token
+	l.mu.RUnlock()
+	if token == "" {
+		return false
+	}
+	auth := r.Header.Get("Authorization")
+	const prefix = "Bearer "
+	if len(auth) < len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(auth[len(prefix):]), []byte(token)) == 1
+}
+
+func (l *logrusImpl) putLevel(r *http.Request) error {
+	var req levelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return err
+	}
+	var revert time.Duration
+	if req.Revert != "" {
+		d, err := time.ParseDuration(req.Revert)
+		if err != nil {
+			return err
+		}
+		revert = d
+	}
+	if req.Level == "" && req.Logger != "" {
+		l.levels.reset(req.Logger)
+		return nil
+	}
+	lvl, err := parseLevel(req.Level)
+	if err != nil {
+		return err
+	}
+	l.levels.set(req.Logger, lvl, revert)
+	l.log.Info(OK, infoLogger, levelMsg(req.Logger, lvl))
+	return nil
+}
@@ -0,0 +1,260 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	lr "github.com/sirupsen/logrus"
+)
+
+const (
+	// HTTPSinkLines posts newline delimited entries
+	HTTPSinkLines string = `lines`
+	// HTTPSinkLoki posts entries to Loki push API, streams are labelled by level
+	HTTPSinkLoki string = `loki`
+
+	defaultSinkTimeout       = 5 * time.Second
+	defaultHTTPBatchSize     = 100
+	defaultHTTPBatchInterval = time.Second
+)
+
+// HTTPSinkOptions defines sink pushing batches of entries over HTTP
+type HTTPSinkOptions struct {
+	URL string
+	// Method default POST
+	Method string
+	// Headers e.g. authorization or tenant id
+	Headers map[string]string
+	// Format is lines or loki, default lines
+	Format string
+	// Labels of loki streams
+	Labels map[string]string
+	// BatchSize sends batch once it has the number of entries, default 100
+	BatchSize int
+	// BatchInterval sends pending entries every interval, default 1s
+	BatchInterval time.Duration
+	// MaxPending drops oldest entries while endpoint is unavailable, default 10 batches
+	MaxPending int
+	// Timeout of every request, default 5s
+	Timeout time.Duration
+}
+
+type httpEntry struct {
+	seq   uint64
+	t     time.Time
+	level string
+	line  []byte
+}
+
+// httpSink batches entries and pushes them from a background goroutine
+type httpSink struct {
+	mu      *sync.Mutex
+	sendMu  *sync.Mutex
+	opt     HTTPSinkOptions
+	client  *http.Client
+	pending []httpEntry
+	seq     uint64
+	flushc  chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// NewHTTPSink returns Sink pushing batches of entries to opt.URL
+func NewHTTPSink(opt HTTPSinkOptions) (Sink, error) {
+	if opt.URL == "" {
+		return nil, fmt.Errorf(`http sink requires url`)
+	}
+	if opt.Method == "" {
+		opt.Method = http.MethodPost
+	}
+	if opt.Format == "" {
+		opt.Format = HTTPSinkLines
+	}
+	if opt.BatchSize <= 0 {
+		opt.BatchSize = defaultHTTPBatchSize
+	}
+	if opt.BatchInterval <= 0 {
+		opt.BatchInterval = defaultHTTPBatchInterval
+	}
+	if opt.MaxPending <= 0 {
+		opt.MaxPending = 10 * opt.BatchSize
+	}
+	if opt.Timeout <= 0 {
+		opt.Timeout = defaultSinkTimeout
+	}
+	s := &httpSink{
+		mu:      &sync.Mutex{},
+		sendMu:  &sync.Mutex{},
+		opt:     opt,
+		client:  &http.Client{Timeout: opt.Timeout},
+		flushc:  make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go s.run()
+	return s, nil
+}
+
+func (s *httpSink) Write(level string, p []byte) error {
+	line := make([]byte, len(p))
+	copy(line, p)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.pending) >= s.opt.MaxPending {
+		if lvl, err := lr.ParseLevel(s.pending[0].level); err == nil {
+			recordDropped(lvl)
+		}
+		s.pending = s.pending[1:]
+	}
+	s.seq++
+	s.pending = append(s.pending, httpEntry{seq: s.seq, t: time.Now(), level: level, line: bytes.TrimRight(line, "\n")})
+	if len(s.pending) >= s.opt.BatchSize {
+		select {
+		case s.flushc <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+func (s *httpSink) run() {
+	defer close(s.stopped)
+	ticker := time.NewTicker(s.opt.BatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.flushc:
+		case <-s.done:
+			s.flush()
+			return
+		}
+		s.flush()
+	}
+}
+
+// Flush sends pending entries, it is called before the process exits
+func (s *httpSink) Flush() error {
+	s.flush()
+	return nil
+}
+
+// flush sends pending entries in batches, failed batches are kept for the next flush
+func (s *httpSink) flush() {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	for {
+		s.mu.Lock()
+		n := len(s.pending)
+		if n > s.opt.BatchSize {
+			n = s.opt.BatchSize
+		}
+		batch := s.pending[:n:n]
+		s.mu.Unlock()
+		if n == 0 {
+			return
+		}
+		if err := s.send(batch); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return
+		}
+		s.mu.Lock()
+		// pending may have been trimmed by MaxPending while sending
+		last := batch[n-1].seq
+		for len(s.pending) > 0 && s.pending[0].seq <= last {
+			s.pending = s.pending[1:]
+		}
+		s.mu.Unlock()
+		if n < s.opt.BatchSize {
+			return
+		}
+	}
+}
+
+func (s *httpSink) send(batch []httpEntry) error {
+	body, contentType, err := s.encode(batch)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.opt.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, s.opt.Method, s.opt.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range s.opt.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf(`http sink %s responded %s`, s.opt.URL, resp.Status)
+	}
+	return nil
+}
+
+type lokiPush struct {
+	Streams []lokiStream `json:"streams"`
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+func (s *httpSink) encode(batch []httpEntry) ([]byte, string, error) {
+	if s.opt.Format != HTTPSinkLoki {
+		buf := &bytes.Buffer{}
+		for _, e := range batch {
+			buf.Write(e.line)
+			buf.WriteByte('\n')
+		}
+		return buf.Bytes(), "application/x-ndjson", nil
+	}
+	streams := make(map[string]*lokiStream)
+	push := lokiPush{}
+	for _, e := range batch {
+		st, ok := streams[e.level]
+		if !ok {
+			labels := make(map[string]string, len(s.opt.Labels)+1)
+			for k, v := range s.opt.Labels {
+				labels[k] = v
+			}
+			labels["level"] = e.level
+			st = &lokiStream{Stream: labels}
+			streams[e.level] = st
+		}
+		st.Values = append(st.Values, [2]string{strconv.FormatInt(e.t.UnixNano(), 10), string(e.line)})
+	}
+	for _, st := range streams {
+		push.Streams = append(push.Streams, *st)
+	}
+	bs, err := json.Marshal(push)
+	return bs, "application/json", err
+}
+
+// Close sends pending entries and stops background goroutine
+func (s *httpSink) Close() error {
+	select {
+	case <-s.done:
+		return nil
+	default:
+	}
+	close(s.done)
+	<-s.stopped
+	return nil
+}
@@ -0,0 +1,146 @@
+package logger
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+type pushRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// newPushServer records requests and responds with codes in order, then with 204
+func newPushServer(t *testing.T, codes ...int) (*httptest.Server, chan pushRequest) {
+	t.Helper()
+	requests := make(chan pushRequest, 16)
+	mu := &sync.Mutex{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests <- pushRequest{header: r.Header.Clone(), body: body}
+		mu.Lock()
+		code := http.StatusNoContent
+		if len(codes) > 0 {
+			code, codes = codes[0], codes[1:]
+		}
+		mu.Unlock()
+		w.WriteHeader(code)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, requests
+}
+
+func receivePush(t *testing.T, requests chan pushRequest) pushRequest {
+	t.Helper()
+	select {
+	case req := <-requests:
+		return req
+	case <-time.After(2 * time.Second):
+		t.Fatal("http sink did not push")
+		return pushRequest{}
+	}
+}
+
+func TestHTTPSinkLoki(t *testing.T) {
+	srv, requests := newPushServer(t)
+	s, err := NewHTTPSink(HTTPSinkOptions{
+		URL:           srv.URL + "/loki/api/v1/push",
+		Format:        HTTPSinkLoki,
+		Headers:       map[string]string{"X-Scope-OrgID": "tenant"},
+		Labels:        map[string]string{"app": "test"},
+		BatchSize:     3,
+		BatchInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPSink() error = %v", err)
+	}
+	defer s.Close()
+
+	before := time.Now()
+	s.Write(LevelInfo, []byte("first\n"))
+	s.Write(LevelError, []byte("failed"))
+	// full batch is pushed without waiting for BatchInterval
+	s.Write(LevelInfo, []byte("second\n"))
+
+	req := receivePush(t, requests)
+	if got := req.header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+	if got := req.header.Get("X-Scope-OrgID"); got != "tenant" {
+		t.Errorf("X-Scope-OrgID = %q, want tenant", got)
+	}
+	var push lokiPush
+	if err := json.Unmarshal(req.body, &push); err != nil {
+		t.Fatalf("invalid loki push %s: %v", req.body, err)
+	}
+	lines := make(map[string][]string)
+	for _, st := range push.Streams {
+		if st.Stream["app"] != "test" {
+			t.Errorf("stream labels %v have no app label", st.Stream)
+		}
+		level := st.Stream["level"]
+		for _, v := range st.Values {
+			ts, err := strconv.ParseInt(v[0], 10, 64)
+			if err != nil || time.Unix(0, ts).Before(before) {
+				t.Errorf("timestamp %q of %q is not unix nanoseconds of the entry", v[0], v[1])
+			}
+			lines[level] = append(lines[level], v[1])
+		}
+	}
+	if got := lines[LevelInfo]; len(got) != 2 || got[0] != "first" || got[1] != "second" {
+		t.Errorf("info stream = %q, want [first second]", got)
+	}
+	if got := lines[LevelError]; len(got) != 1 || got[0] != "failed" {
+		t.Errorf("error stream = %q, want [failed]", got)
+	}
+}
+
+func TestHTTPSinkLinesFlushedOnClose(t *testing.T) {
+	srv, requests := newPushServer(t)
+	s, err := NewHTTPSink(HTTPSinkOptions{URL: srv.URL, BatchInterval: time.Hour})
+	if err != nil {
+		t.Fatalf("NewHTTPSink() error = %v", err)
+	}
+	s.Write(LevelInfo, []byte(`{"msg":"a"}`+"\n"))
+	s.Write(LevelWarn, []byte(`{"msg":"b"}`+"\n"))
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	req := receivePush(t, requests)
+	if got := req.header.Get("Content-Type"); got != "application/x-ndjson" {
+		t.Errorf("Content-Type = %q, want application/x-ndjson", got)
+	}
+	if want := "{\"msg\":\"a\"}\n{\"msg\":\"b\"}\n"; string(req.body) != want {
+		t.Errorf("body = %q, want %q", req.body, want)
+	}
+}
+
+func TestHTTPSinkRetriesFailedBatch(t *testing.T) {
+	srv, requests := newPushServer(t, http.StatusServiceUnavailable)
+	s, err := NewHTTPSink(HTTPSinkOptions{URL: srv.URL, BatchInterval: time.Hour, MaxPending: 2})
+	if err != nil {
+		t.Fatalf("NewHTTPSink() error = %v", err)
+	}
+	defer s.Close()
+	f := s.(Flusher)
+
+	s.Write(LevelInfo, []byte("a"))
+	f.Flush()
+	if req := receivePush(t, requests); string(req.body) != "a\n" {
+		t.Fatalf("first push = %q, want a", req.body)
+	}
+	// failed batch is kept, oldest entry is dropped beyond MaxPending
+	s.Write(LevelInfo, []byte("b"))
+	s.Write(LevelInfo, []byte("c"))
+	f.Flush()
+	if req := receivePush(t, requests); string(req.body) != "b\nc\n" {
+		t.Errorf("retried push = %q, want b and c", req.body)
+	}
+}
@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	errors "github.com/mytoko2796/sdk-go/stdlib/error"
+)
+
+const (
+	defaultJournaldAddress string = `/run/systemd/journal/socket`
+)
+
+// JournaldOptions defines journald sink using journal native protocol
+type JournaldOptions struct {
+	// Address of journal socket, default /run/systemd/journal/socket
+	Address string
+	// Identifier is SYSLOG_IDENTIFIER, default is executable name
+	Identifier string
+}
+
+// journaldSink writes entries as journal native protocol datagrams
+type journaldSink struct {
+	mu   *sync.Mutex
+	opt  JournaldOptions
+	conn *net.UnixConn
+}
+
+// NewJournaldSink returns Sink writing to systemd journal
+func NewJournaldSink(opt JournaldOptions) (Sink, error) {
+	if opt.Address == "" {
+		opt.Address = defaultJournaldAddress
+	}
+	if opt.Identifier == "" {
+		opt.Identifier = filepath.Base(os.Args[0])
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: opt.Address, Net: "unixgram"})
+	if err != nil {
+		return nil, errors.Wrap(err, `cannot connect to journald %s`, opt.Address)
+	}
+	return &journaldSink{
+		mu:   &sync.Mutex{},
+		opt:  opt,
+		conn: conn,
+	}, nil
+}
+
+func (s *journaldSink) Write(level string, p []byte) error {
+	severity, ok := syslogSeverity[level]
+	if !ok {
+		severity = syslogSeverity[LevelInfo]
+	}
+	buf := &bytes.Buffer{}
+	writeJournalField(buf, `MESSAGE`, bytes.TrimRight(p, "\n"))
+	writeJournalField(buf, `PRIORITY`, []byte(strconv.Itoa(severity)))
+	writeJournalField(buf, `SYSLOG_IDENTIFIER`, []byte(s.opt.Identifier))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.conn.Write(buf.Bytes())
+	return err
+}
+
+// writeJournalField writes KEY=value, values containing newlines use
+// KEY\n<little endian uint64 length><value>\n
+func writeJournalField(buf *bytes.Buffer, key string, val []byte) {
+	buf.WriteString(key)
+	if bytes.IndexByte(val, '\n') < 0 {
+		buf.WriteByte('=')
+		buf.Write(val)
+		buf.WriteByte('\n')
+		return
+	}
+	buf.WriteByte('\n')
+	binary.Write(buf, binary.LittleEndian, uint64(len(val)))
+	buf.Write(val)
+	buf.WriteByte('\n')
+}
+
+func (s *journaldSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conn.Close()
+}
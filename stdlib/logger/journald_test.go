@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// parseJournalFields decodes datagram of journal native protocol
+func parseJournalFields(t *testing.T, p []byte) map[string]string {
+	t.Helper()
+	fields := make(map[string]string)
+	for len(p) > 0 {
+		i := bytes.IndexAny(p, "=\n")
+		if i < 0 {
+			t.Fatalf("field without separator in %q", p)
+		}
+		key := string(p[:i])
+		if p[i] == '=' {
+			end := bytes.IndexByte(p[i+1:], '\n')
+			if end < 0 {
+				t.Fatalf("field %s is not terminated", key)
+			}
+			fields[key] = string(p[i+1 : i+1+end])
+			p = p[i+1+end+1:]
+			continue
+		}
+		p = p[i+1:]
+		if len(p) < 8 {
+			t.Fatalf("field %s has no length", key)
+		}
+		n := binary.LittleEndian.Uint64(p[:8])
+		p = p[8:]
+		if uint64(len(p)) < n+1 || p[n] != '\n' {
+			t.Fatalf("field %s of length %d is not terminated", key, n)
+		}
+		fields[key] = string(p[:n])
+		p = p[n+1:]
+	}
+	return fields
+}
+
+func TestJournaldSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	s, err := NewJournaldSink(JournaldOptions{Address: path, Identifier: "app"})
+	if err != nil {
+		t.Fatalf("NewJournaldSink() error = %v", err)
+	}
+	defer s.Close()
+
+	tests := []struct {
+		name     string
+		level    string
+		entry    string
+		message  string
+		priority string
+	}{
+		{name: "single line", level: LevelWarn, entry: "disk is almost full\n", message: "disk is almost full", priority: "4"},
+		{name: "multi line", level: LevelError, entry: "panic\ngoroutine 1\n", message: "panic\ngoroutine 1", priority: "3"},
+		{name: "unknown level", level: "verbose", entry: "entry", message: "entry", priority: "6"},
+	}
+	buf := make([]byte, 4096)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.Write(tt.level, []byte(tt.entry)); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			conn.SetReadDeadline(time.Now().Add(time.Second))
+			n, err := conn.Read(buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fields := parseJournalFields(t, buf[:n])
+			want := map[string]string{
+				"MESSAGE":           tt.message,
+				"PRIORITY":          tt.priority,
+				"SYSLOG_IDENTIFIER": "app",
+			}
+			for k, v := range want {
+				if fields[k] != v {
+					t.Errorf("%s = %q, want %q", k, fields[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestJournaldSinkNoSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.sock")
+	if _, err := NewJournaldSink(JournaldOptions{Address: path}); err == nil {
+		t.Fatal("NewJournaldSink() error = nil, want error for missing socket")
+	}
+}
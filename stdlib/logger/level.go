@@ -2,6 +2,10 @@ package logger
 
 import (
 	"fmt"
+	"strings"
+	"sync"
+	"time"
+
 	lr "github.com/sirupsen/logrus"
 
 	errors "github.com/mytoko2796/sdk-go/stdlib/error"
@@ -54,6 +58,170 @@ func (l *logrusImpl) setLevelLogrus() {
 		err := errors.Wrapf(ErrUnknownLevel, errLogger, FAILED)
 		l.log.Panic(err)
 	}
-	//set logrus log level
-	l.logger.SetLevel(lrLevel)
+	//set global log level, levels of named loggers are kept
+	l.levels.set("", lrLevel, 0)
+}
+
+// levels holds global level and levels of named loggers.
+// Logrus level is kept at the most verbose of them and every logger filters by its own level.
+type levels struct {
+	mu      *sync.RWMutex
+	logger  *lr.Logger
+	global  lr.Level
+	named   map[string]lr.Level
+	reverts map[string]*time.Timer
+}
+
+func newLevels(logger *lr.Logger) *levels {
+	return &levels{
+		mu:      &sync.RWMutex{},
+		logger:  logger,
+		global:  logger.GetLevel(),
+		named:   make(map[string]lr.Level),
+		reverts: make(map[string]*time.Timer),
+	}
+}
+
+// level returns level of named logger. Names are dot separated and fall back to
+// their parent, e.g. sql.pool uses level of sql unless it has its own.
+func (ls *levels) level(name string) lr.Level {
+	ls.mu.RLock()
+	defer ls.mu.RUnlock()
+	for name != "" {
+		if lvl, ok := ls.named[name]; ok {
+			return lvl
+		}
+		i := strings.LastIndex(name, ".")
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return ls.global
+}
+
+func (ls *levels) enabled(name string, level lr.Level) bool {
+	return ls.level(name) >= level
+}
+
+// set changes level of named logger, empty name changes global level.
+// Previous level is restored after revert if it is not zero.
+func (ls *levels) set(name string, level lr.Level, revert time.Duration) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	if t, ok := ls.reverts[name]; ok {
+		t.Stop()
+		delete(ls.reverts, name)
+	}
+	prev, hadPrev := ls.get(name)
+	ls.put(name, level, true)
+	if revert > 0 {
+		var t *time.Timer
+		t = time.AfterFunc(revert, func() {
+			ls.mu.Lock()
+			defer ls.mu.Unlock()
+			// timer may have been replaced by a later change
+			if ls.reverts[name] != t {
+				return
+			}
+			delete(ls.reverts, name)
+			ls.put(name, prev, hadPrev)
+		})
+		ls.reverts[name] = t
+	}
+}
+
+// reset removes level of named logger so it follows its parent again
+func (ls *levels) reset(name string) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	if t, ok := ls.reverts[name]; ok {
+		t.Stop()
+		delete(ls.reverts, name)
+	}
+	if name != "" {
+		ls.put(name, 0, false)
+	}
+}
+
+func (ls *levels) get(name string) (lr.Level, bool) {
+	if name == "" {
+		return ls.global, true
+	}
+	lvl, ok := ls.named[name]
+	return lvl, ok
+}
+
+// put stores level and updates logrus level, it must be called with lock held
+func (ls *levels) put(name string, level lr.Level, ok bool) {
+	switch {
+	case name == "":
+		ls.global = level
+	case ok:
+		ls.named[name] = level
+	default:
+		delete(ls.named, name)
+	}
+	max := ls.global
+	for _, lvl := range ls.named {
+		if lvl > max {
+			max = lvl
+		}
+	}
+	ls.logger.SetLevel(max)
+}
+
+// snapshot returns global level and levels of named loggers
+func (ls *levels) snapshot() (string, map[string]string) {
+	ls.mu.RLock()
+	defer ls.mu.RUnlock()
+	named := make(map[string]string, len(ls.named))
+	for name, lvl := range ls.named {
+		named[name] = lvl.String()
+	}
+	return ls.global.String(), named
+}
+
+// parseLevel parses level names such as debug or warn
+func parseLevel(level string) (lr.Level, error) {
+	lvl, err := lr.ParseLevel(level)
+	if err != nil {
+		return lvl, errors.Wrapf(ErrUnknownLevel, errLogger, FAILED)
+	}
+	return lvl, nil
+}
+
+// Named returns child logger with its own level. Names of nested loggers are
+// joined by dot, e.g. Named("sql").Named("pool") is sql.pool.
+func (l *logrusImpl) Named(name string) Logger {
+	if l.name != "" {
+		name = l.name + "." + name
+	}
+	return &logrusImpl{
+		core: l.core,
+		log:  l.log.WithField(fieldLogger, name),
+		name: name,
+	}
+}
+
+// SetLevel changes level of this logger, root logger changes global level.
+// Level is reverted after revert if it is not zero.
+func (l *logrusImpl) SetLevel(level string, revert time.Duration) error {
+	lvl, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+	l.levels.set(l.name, lvl, revert)
+	l.log.Info(OK, infoLogger, levelMsg(l.name, lvl))
+	return nil
+}
+
+// levelMsg returns e.g. sql[DEBUG] for logs of level changes
+func levelMsg(name string, lvl lr.Level) string {
+	return fmt.Sprintf("%s[%s]", name, strings.ToUpper(lvl.String()))
+}
+
+// Level returns effective level of this logger
+func (l *logrusImpl) Level() string {
+	return l.levels.level(l.name).String()
 }
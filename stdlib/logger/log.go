@@ -2,6 +2,7 @@ package logger
 
 import (
 	"context"
+	"fmt"
 
 	lr "github.com/sirupsen/logrus"
 )
@@ -19,17 +20,45 @@ const (
 func (l *logrusImpl) parseContextFields(ctx context.Context) *lr.Entry {
 	doLog := l.log
 	if ctx != nil {
-		for k, v := range l.opt.ContextFields {
+		opt := l.ctxOptions.Load()
+		for k, v := range opt.fields {
 			if val := ctx.Value(v); val != nil {
 				doLog = doLog.WithField(k, val)
 			}
 		}
+		if fields := opt.trace.traceFields(ctx); fields != nil {
+			doLog = doLog.WithFields(fields)
+		}
 	}
 	return doLog
 }
 
+// logLevel writes entry of level below fatal if level is enabled and entry is not sampled out.
+// Sampled out entries are still counted, so the count reflects logged calls.
+func (l *logrusImpl) logLevel(ctx context.Context, level lr.Level, msg string, kv []interface{}) {
+	if !l.levels.enabled(l.name, level) {
+		return
+	}
+	if s := l.sampler.Load(); s != nil && !s.allow(level, msg) {
+		recordCount(level, l.name)
+		return
+	}
+	doLog := l.parseContextFields(ctx)
+	if len(kv) > 0 {
+		doLog = doLog.WithFields(parseKeyValues(kv))
+	}
+	doLog.Log(level, msg)
+}
+
+// logArgs formats variadic arguments only if level is enabled
+func (l *logrusImpl) logArgs(ctx context.Context, level lr.Level, v []interface{}) {
+	if l.levels.enabled(l.name, level) {
+		l.logLevel(ctx, level, fmt.Sprint(v...), nil)
+	}
+}
+
 func (l *logrusImpl) TraceWithContext(ctx context.Context, v ...interface{}) {
-	l.parseContextFields(ctx).Trace(v...)
+	l.logArgs(ctx, lr.TraceLevel, v)
 }
 
 func (l *logrusImpl) Trace(v ...interface{}) {
@@ -37,7 +66,7 @@ func (l *logrusImpl) Trace(v ...interface{}) {
 }
 
 func (l *logrusImpl) DebugWithContext(ctx context.Context, v ...interface{}) {
-	l.parseContextFields(ctx).Debug(v...)
+	l.logArgs(ctx, lr.DebugLevel, v)
 }
 
 func (l *logrusImpl) Debug(v ...interface{}) {
@@ -45,7 +74,7 @@ func (l *logrusImpl) Debug(v ...interface{}) {
 }
 
 func (l *logrusImpl) InfoWithContext(ctx context.Context, v ...interface{}) {
-	l.parseContextFields(ctx).Info(v...)
+	l.logArgs(ctx, lr.InfoLevel, v)
 }
 
 func (l *logrusImpl) Info(v ...interface{}) {
@@ -53,7 +82,7 @@ func (l *logrusImpl) Info(v ...interface{}) {
 }
 
 func (l *logrusImpl) WarnWithContext(ctx context.Context, v ...interface{}) {
-	l.parseContextFields(ctx).Warn(v...)
+	l.logArgs(ctx, lr.WarnLevel, v)
 }
 
 func (l *logrusImpl) Warn(v ...interface{}) {
@@ -61,7 +90,7 @@ func (l *logrusImpl) Warn(v ...interface{}) {
 }
 
 func (l *logrusImpl) ErrorWithContext(ctx context.Context, v ...interface{}) {
-	l.parseContextFields(ctx).Error(v...)
+	l.logArgs(ctx, lr.ErrorLevel, v)
 }
 
 func (l *logrusImpl) Error(v ...interface{}) {
@@ -77,9 +106,67 @@ func (l *logrusImpl) Fatal(v ...interface{}) {
 }
 
 func (l *logrusImpl) PanicWithContext(ctx context.Context, v ...interface{}) {
+	defer l.flush()
 	l.parseContextFields(ctx).Panic(v...)
 }
 
 func (l *logrusImpl) Panic(v ...interface{}) {
 	l.PanicWithContext(nil, v...)
 }
+
+func (l *logrusImpl) TracewWithContext(ctx context.Context, msg string, kv ...interface{}) {
+	l.logLevel(ctx, lr.TraceLevel, msg, kv)
+}
+
+func (l *logrusImpl) Tracew(msg string, kv ...interface{}) {
+	l.TracewWithContext(nil, msg, kv...)
+}
+
+func (l *logrusImpl) DebugwWithContext(ctx context.Context, msg string, kv ...interface{}) {
+	l.logLevel(ctx, lr.DebugLevel, msg, kv)
+}
+
+func (l *logrusImpl) Debugw(msg string, kv ...interface{}) {
+	l.DebugwWithContext(nil, msg, kv...)
+}
+
+func (l *logrusImpl) InfowWithContext(ctx context.Context, msg string, kv ...interface{}) {
+	l.logLevel(ctx, lr.InfoLevel, msg, kv)
+}
+
+func (l *logrusImpl) Infow(msg string, kv ...interface{}) {
+	l.InfowWithContext(nil, msg, kv...)
+}
+
+func (l *logrusImpl) WarnwWithContext(ctx context.Context, msg string, kv ...interface{}) {
+	l.logLevel(ctx, lr.WarnLevel, msg, kv)
+}
+
+func (l *logrusImpl) Warnw(msg string, kv ...interface{}) {
+	l.WarnwWithContext(nil, msg, kv...)
+}
+
+func (l *logrusImpl) ErrorwWithContext(ctx context.Context, msg string, kv ...interface{}) {
+	l.logLevel(ctx, lr.ErrorLevel, msg, kv)
+}
+
+func (l *logrusImpl) Errorw(msg string, kv ...interface{}) {
+	l.ErrorwWithContext(nil, msg, kv...)
+}
+
+func (l *logrusImpl) FatalwWithContext(ctx context.Context, msg string, kv ...interface{}) {
+	l.parseContextFields(ctx).WithFields(parseKeyValues(kv)).Fatal(msg)
+}
+
+func (l *logrusImpl) Fatalw(msg string, kv ...interface{}) {
+	l.FatalwWithContext(nil, msg, kv...)
+}
+
+func (l *logrusImpl) PanicwWithContext(ctx context.Context, msg string, kv ...interface{}) {
+	defer l.flush()
+	l.parseContextFields(ctx).WithFields(parseKeyValues(kv)).Panic(msg)
+}
+
+func (l *logrusImpl) Panicw(msg string, kv ...interface{}) {
+	l.PanicwWithContext(nil, msg, kv...)
+}
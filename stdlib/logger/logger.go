@@ -4,8 +4,12 @@ import (
 	"context"
 	"io"
 	"io/ioutil"
+	"net/http"
 	"os"
+	"reflect"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	lr "github.com/sirupsen/logrus"
 )
@@ -15,10 +19,9 @@ const (
 	errLogger  string = `%s Logger Error`
 	OK         string = "[OK]"
 	FAILED     string = "[FAILED]"
-)
 
-var (
-	once = sync.Once{}
+	// fieldLogger holds name of named logger
+	fieldLogger string = `logger`
 )
 
 
@@ -40,58 +43,160 @@ type Logger interface {
 	Error(v ...interface{})
 	Fatal(v ...interface{})
 	Panic(v ...interface{})
+	// With returns child logger that adds fields to every entry
+	With(fields ...Field) Logger
+	// key-value methods take a message followed by alternating keys and values or Field
+	TracewWithContext(ctx context.Context, msg string, kv ...interface{})
+	DebugwWithContext(ctx context.Context, msg string, kv ...interface{})
+	InfowWithContext(ctx context.Context, msg string, kv ...interface{})
+	WarnwWithContext(ctx context.Context, msg string, kv ...interface{})
+	ErrorwWithContext(ctx context.Context, msg string, kv ...interface{})
+	FatalwWithContext(ctx context.Context, msg string, kv ...interface{})
+	PanicwWithContext(ctx context.Context, msg string, kv ...interface{})
+	Tracew(msg string, kv ...interface{})
+	Debugw(msg string, kv ...interface{})
+	Infow(msg string, kv ...interface{})
+	Warnw(msg string, kv ...interface{})
+	Errorw(msg string, kv ...interface{})
+	Fatalw(msg string, kv ...interface{})
+	Panicw(msg string, kv ...interface{})
+	// Named returns child logger with its own level
+	Named(name string) Logger
+	// SetLevel changes level of this logger, level is reverted after revert if it is not zero
+	SetLevel(level string, revert time.Duration) error
+	// Level returns effective level of this logger
+	Level() string
+	// HTTPHandler returns handler to read and change levels at runtime, authenticated by HTTP.Token
+	HTTPHandler() http.HandlerFunc
 }
 
 type logrusImpl struct {
+	*core
+	// log holds fields of this logger
+	log *lr.Entry
+	// name of logger created by Named, root logger has no name
+	name string
+}
+
+// core holds state shared by a logger and its children created by With
+type core struct {
 	mu     *sync.RWMutex
 	logger *lr.Logger
-	log    *lr.Entry
 	opt    Options
-	file   *os.File
+	file   *rotateWriter
+	async  *asyncWriter
 	writer *io.PipeWriter
+	// sampler holds nil if sampling is disabled, it is read by every entry without lock
+	sampler atomic.Pointer[sampler]
+	// ctxOptions holds context fields and trace options, it is read by every entry without lock
+	ctxOptions atomic.Pointer[contextOptions]
+	levels     *levels
+	sinks      *sinkHook
+	scrub      *scrubHook
 }
 
 type Options struct {
 	Output string
 	Formatter string
+	// FormatterOptions customizes timestamp, field names and caller of the formatter
+	FormatterOptions FormatterOptions
 	Level string
 	LogOutputPath string
 	DefaultFields map[string]string
 	ContextFields map[string]string
+	// Trace defines fields added by *WithContext methods from OpenCensus span in context
+	Trace TraceOptions
+	// File defines rotation of file output
+	File FileOptions
+	// Async defines asynchronous buffered output
+	Async AsyncOptions
+	// Sampling limits repeated entries
+	Sampling SamplingOptions
+	// Sinks are additional outputs, each with its own level and formatter
+	Sinks []SinkOptions
+	// Scrub masks secrets and personal data in message and text fields of every entry
+	Scrub ScrubOptions
+	// HTTP defines authentication of HTTPHandler
+	HTTP HTTPOptions
 }
 
 func Init(opt Options) Logger{
-	var lg *logrusImpl
-	once.Do(func() {
-		logrus := lr.New()
-		log := logrus.WithFields(lr.Fields{})
-		lg = &logrusImpl{
+	logrus := lr.New()
+	log := logrus.WithFields(lr.Fields{})
+	lg := &logrusImpl{
+		core: &core{
 			mu:     &sync.RWMutex{},
 			logger: logrus,
-			log:    log,
 			opt:    opt,
-		}
+			levels: newLevels(logrus),
+			sinks:  newSinkHook(),
+			scrub:  newScrubHook(),
+		},
+		log: log,
+	}
 
-		lg.logger.SetOutput(ioutil.Discard)
-		lg.setDefaultOptions()
-		lg.applyOptions()
-	})
+	lg.logger.SetOutput(ioutil.Discard)
+	lg.setFormatter(logrus.Formatter)
+	lg.logger.ExitFunc = lg.exit
+	// scrub hook goes first so other hooks and sinks only see masked entries
+	lg.logger.AddHook(lg.scrub)
+	lg.logger.AddHook(&countHook{})
+	lg.logger.AddHook(lg.sinks)
+	lg.setDefaultOptions()
+	lg.applyOptions()
 
 	return lg
 }
 
+// SetOptions applies only options which changed, so unchanged output, sinks or
+// sampler are kept, their banners are not logged again and runtime levels stay.
 func (l *logrusImpl) SetOptions(opt Options) {
 	l.mu.Lock()
+	prev := l.opt
 	l.opt = opt
 	l.mu.Unlock()
-	l.applyOptions()
+	l.setDefaultOptions()
+	l.applyChangedOptions(prev)
 }
 
 func (l *logrusImpl) applyOptions() {
 	l.convertAndSetOutput()
 	l.convertAndSetFormatter()
 	l.convertAndSetLevel()
+	l.setSampler()
+	l.convertAndSetScrubber()
+	l.convertAndSetSinks()
 	l.setDefaultFields()
+	l.setContextOptions()
+}
+
+func (l *logrusImpl) applyChangedOptions(prev Options) {
+	opt := l.opt
+	if opt.Output != prev.Output || opt.LogOutputPath != prev.LogOutputPath ||
+		!reflect.DeepEqual(opt.File, prev.File) || !reflect.DeepEqual(opt.Async, prev.Async) {
+		l.convertAndSetOutput()
+	}
+	if opt.Formatter != prev.Formatter || !reflect.DeepEqual(opt.FormatterOptions, prev.FormatterOptions) {
+		l.convertAndSetFormatter()
+	}
+	if opt.Level != prev.Level {
+		l.convertAndSetLevel()
+	}
+	if !reflect.DeepEqual(opt.Sampling, prev.Sampling) {
+		l.setSampler()
+	}
+	if !reflect.DeepEqual(opt.Scrub, prev.Scrub) {
+		l.convertAndSetScrubber()
+	}
+	if !reflect.DeepEqual(opt.Sinks, prev.Sinks) {
+		l.convertAndSetSinks()
+	}
+	if !reflect.DeepEqual(opt.DefaultFields, prev.DefaultFields) {
+		l.setDefaultFields()
+	}
+	if !reflect.DeepEqual(opt.ContextFields, prev.ContextFields) || opt.Trace != prev.Trace {
+		l.setContextOptions()
+	}
 }
 
 func (l *logrusImpl) setDefaultOptions() {
@@ -109,9 +214,35 @@ func (l *logrusImpl) setDefaultOptions() {
 	}
 }
 func (l *logrusImpl) Stop() {
-	l.writer.Close()
-	if l.file != nil {
-		l.file.Close()
+	l.sinks.set(nil)
+	l.swapSampler(nil)
+	l.mu.RLock()
+	async, file := l.async, l.file
+	l.mu.RUnlock()
+	if async != nil {
+		async.Close()
+	}
+	if l.writer != nil {
+		l.writer.Close()
+	}
+	if file != nil {
+		file.Close()
 	}
 }
 
+
+// flush writes buffered async entries, it is called before the process exits or panics
+func (l *logrusImpl) flush() {
+	l.mu.RLock()
+	async := l.async
+	l.mu.RUnlock()
+	if async != nil {
+		async.Flush()
+	}
+	l.sinks.flush()
+}
+
+func (l *logrusImpl) exit(code int) {
+	l.flush()
+	os.Exit(code)
+}
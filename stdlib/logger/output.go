@@ -2,6 +2,7 @@ package logger
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 
@@ -28,20 +29,43 @@ func (l *logrusImpl) convertAndSetOutput() {
 	switch l.opt.Output {
 	case OutputDiscard:
 		l.log.Info(OK, infoLogger, outputDiscard)
-		l.logger.SetOutput(ioutil.Discard)
+		l.setOutput(ioutil.Discard, nil)
 	case OutputStdout:
-		l.logger.SetOutput(os.Stdout)
+		l.setOutput(os.Stdout, nil)
 		l.log.Info(OK, infoLogger, outputStdout)
 	case OutputFile:
-		f, err := os.OpenFile(l.opt.LogOutputPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0755)
+		f, err := newRotateWriter(l.opt.LogOutputPath, l.opt.File)
 		if err != nil {
 			err = error.Wrapf(err, errLogger, FAILED)
 			l.log.Panic(err)
 		}
-		l.file = f
-		l.logger.SetOutput(l.file)
+		l.setOutput(f, f)
 		l.log.Info(OK, infoLogger, outputFile, l.opt.LogOutputPath)
 	default:
 		l.log.Panic(ErrUnknownOutput)
 	}
-}
\ No newline at end of file
+}
+
+// setOutput sets logrus output, wrapped by asyncWriter if async output is enabled.
+// Both strip the level prefixed by levelFormatter, so output and formatter never
+// have to be swapped together. Previous async output is flushed and previous file
+// is closed once output is replaced, so no entry is written to a closed file.
+func (l *logrusImpl) setOutput(w io.Writer, file *rotateWriter) {
+	var async *asyncWriter
+	var out io.Writer = levelWriter(writerFunc(w))
+	if l.opt.Async.Enabled {
+		async = newAsyncWriter(writerFunc(w), l.opt.Async)
+		out = async
+	}
+	l.mu.Lock()
+	prevAsync, prevFile := l.async, l.file
+	l.async, l.file = async, file
+	l.logger.SetOutput(out)
+	l.mu.Unlock()
+	if prevAsync != nil {
+		prevAsync.Close()
+	}
+	if prevFile != nil {
+		prevFile.Close()
+	}
+}
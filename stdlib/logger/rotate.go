@@ -0,0 +1,300 @@
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	errors "github.com/mytoko2796/sdk-go/stdlib/error"
+)
+
+const (
+	// backupTimeFormat is appended to rotated file names, it has no colons so it is valid on every filesystem
+	backupTimeFormat string = `2006-01-02T15-04-05.000`
+	compressSuffix   string = `.gz`
+
+	defaultFilePerm os.FileMode = 0644
+)
+
+// FileOptions defines rotation and retention of file output
+type FileOptions struct {
+	// MaxSize rotates file once it reaches the size in bytes, zero disables size based rotation
+	MaxSize int64
+	// RotateInterval rotates file on every interval boundary e.g. 24h, zero disables time based rotation
+	RotateInterval time.Duration
+	// MaxBackups is the number of rotated files to keep, zero keeps all of them
+	MaxBackups int
+	// MaxAge removes rotated files older than the age, zero keeps all of them
+	MaxAge time.Duration
+	// Compress gzips rotated files
+	Compress bool
+	// Perm is mode of created log files, default 0644
+	Perm os.FileMode
+	// ReopenOnSignal reopens file on SIGUSR1 after it is moved by external logrotate, unix only
+	ReopenOnSignal bool
+}
+
+// rotateWriter is a file writer that rotates by size and time and cleans up old backups
+type rotateWriter struct {
+	mu       *sync.Mutex
+	path     string
+	opt      FileOptions
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	mill     chan struct{}
+	sig      chan os.Signal
+	done     chan struct{}
+}
+
+func newRotateWriter(path string, opt FileOptions) (*rotateWriter, error) {
+	if opt.Perm == 0 {
+		opt.Perm = defaultFilePerm
+	}
+	w := &rotateWriter{
+		mu:   &sync.Mutex{},
+		path: path,
+		opt:  opt,
+		mill: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	go w.runMill()
+	if opt.ReopenOnSignal {
+		w.sig = make(chan os.Signal, 1)
+		notifyReopen(w.sig)
+		go w.watchSignal()
+	}
+	return w, nil
+}
+
+// open opens or creates log file and keeps its size and modification time
+func (w *rotateWriter) open() error {
+	f, err := os.OpenFile(w.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, w.opt.Perm)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.file = f
+	w.size = fi.Size()
+	w.openedAt = time.Now()
+	if w.size > 0 {
+		w.openedAt = fi.ModTime()
+	}
+	return nil
+}
+
+func (w *rotateWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+	if w.shouldRotate(int64(len(p))) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *rotateWriter) shouldRotate(n int64) bool {
+	if w.size == 0 {
+		return false
+	}
+	if w.opt.MaxSize > 0 && w.size+n > w.opt.MaxSize {
+		return true
+	}
+	if w.opt.RotateInterval > 0 {
+		return !time.Now().Truncate(w.opt.RotateInterval).Equal(w.openedAt.Truncate(w.opt.RotateInterval))
+	}
+	return false
+}
+
+// rotate moves current file to a timestamped backup and opens a new one
+func (w *rotateWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return w.restore(err)
+	}
+	if err := os.Rename(w.path, w.backupName(time.Now())); err != nil {
+		return w.restore(err)
+	}
+	if err := w.open(); err != nil {
+		return w.restore(err)
+	}
+	select {
+	case w.mill <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// restore reopens log file in append mode after failed rotation and returns err,
+// so later writes go on to the current file instead of the closed one
+func (w *rotateWriter) restore(err error) error {
+	if openErr := w.open(); openErr != nil {
+		return errors.Wrap(err, `log file %s is not reopened after failed rotation: %v`, w.path, openErr)
+	}
+	return err
+}
+
+// Reopen reopens log file without rotating it, used after external logrotate moved the file
+func (w *rotateWriter) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return os.ErrClosed
+	}
+	w.file.Close()
+	return w.open()
+}
+
+func (w *rotateWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	if w.sig != nil {
+		signal.Stop(w.sig)
+	}
+	close(w.done)
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+func (w *rotateWriter) watchSignal() {
+	for {
+		select {
+		case <-w.sig:
+			if err := w.Reopen(); err != nil {
+				// logger output is gone at this point, stderr is the only place left
+				io.WriteString(os.Stderr, err.Error()+"\n")
+			}
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// backupName returns e.g. /var/log/app-2006-01-02T15-04-05.000.log for /var/log/app.log
+func (w *rotateWriter) backupName(t time.Time) string {
+	ext := filepath.Ext(w.path)
+	prefix := strings.TrimSuffix(w.path, ext)
+	return prefix + "-" + t.Format(backupTimeFormat) + ext
+}
+
+// runMill compresses and removes backups after every rotation
+func (w *rotateWriter) runMill() {
+	for {
+		select {
+		case <-w.mill:
+			w.millBackups()
+		case <-w.done:
+			return
+		}
+	}
+}
+
+type backupFile struct {
+	path string
+	t    time.Time
+}
+
+// backups returns rotated files of the log file sorted from newest
+func (w *rotateWriter) backups() ([]backupFile, error) {
+	dir := filepath.Dir(w.path)
+	ext := filepath.Ext(w.path)
+	prefix := strings.TrimSuffix(filepath.Base(w.path), ext) + "-"
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var out []backupFile
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		ts := strings.TrimSuffix(strings.TrimSuffix(strings.TrimPrefix(name, prefix), compressSuffix), ext)
+		t, err := time.ParseInLocation(backupTimeFormat, ts, time.Local)
+		if err != nil {
+			continue
+		}
+		out = append(out, backupFile{path: filepath.Join(dir, name), t: t})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].t.After(out[j].t) })
+	return out, nil
+}
+
+func (w *rotateWriter) millBackups() {
+	files, err := w.backups()
+	if err != nil {
+		io.WriteString(os.Stderr, err.Error()+"\n")
+		return
+	}
+	var cutoff time.Time
+	if w.opt.MaxAge > 0 {
+		cutoff = time.Now().Add(-w.opt.MaxAge)
+	}
+	for i, f := range files {
+		if (w.opt.MaxBackups > 0 && i >= w.opt.MaxBackups) || (!cutoff.IsZero() && f.t.Before(cutoff)) {
+			os.Remove(f.path)
+			continue
+		}
+		if w.opt.Compress && !strings.HasSuffix(f.path, compressSuffix) {
+			if err := compressFile(f.path, w.opt.Perm); err != nil {
+				io.WriteString(os.Stderr, err.Error()+"\n")
+			}
+		}
+	}
+}
+
+// compressFile gzips src into src.gz and removes src
+func compressFile(src string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	tmp := src + compressSuffix + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(out)
+	if _, err := io.Copy(gz, in); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, src+compressSuffix); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package logger
+
+import "os"
+
+// notifyReopen does nothing since SIGUSR1 does not exist on this platform
+func notifyReopen(c chan<- os.Signal) {}
@@ -0,0 +1,14 @@
+//go:build unix
+
+package logger
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyReopen relays SIGUSR1 sent by logrotate to c
+func notifyReopen(c chan<- os.Signal) {
+	signal.Notify(c, syscall.SIGUSR1)
+}
@@ -0,0 +1,178 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	errors "github.com/mytoko2796/sdk-go/stdlib/error"
+	lr "github.com/sirupsen/logrus"
+)
+
+const (
+	infoSampling string = `Log Sampling: suppressed`
+
+	defaultSamplingInterval = time.Second
+)
+
+// SamplingOptions limits repeated entries. Entries are keyed by level and message,
+// key-value methods (e.g. Infow) keep variable data out of the message so they are
+// sampled by message template. In every interval the first First entries of a key are
+// written, then every Thereafter-th entry. Suppressed counts are logged every interval.
+type SamplingOptions struct {
+	Enabled bool
+	// Interval resets counters, default 1s
+	Interval time.Duration
+	// First and Thereafter apply to levels without rule in Levels,
+	// such levels are not sampled if both are zero
+	First      int
+	Thereafter int
+	// Levels overrides sampling per level e.g. sample debug aggressively, keys are level names
+	Levels map[string]SamplingRule
+	// PassErrors never samples error level
+	PassErrors bool
+}
+
+// SamplingRule defines how many entries per interval are written.
+// Thereafter zero suppresses every entry after the first ones.
+type SamplingRule struct {
+	First      int
+	Thereafter int
+}
+
+type sampleKey struct {
+	level lr.Level
+	msg   string
+}
+
+type sampleCount struct {
+	n          int
+	suppressed int
+}
+
+// sampler counts entries per key and reports suppressed ones every interval
+type sampler struct {
+	mu     *sync.Mutex
+	opt    SamplingOptions
+	rules  map[lr.Level]SamplingRule
+	counts map[sampleKey]*sampleCount
+	report func(key sampleKey, suppressed int)
+	done   chan struct{}
+}
+
+func newSampler(opt SamplingOptions, report func(key sampleKey, suppressed int)) (*sampler, error) {
+	if opt.Interval <= 0 {
+		opt.Interval = defaultSamplingInterval
+	}
+	s := &sampler{
+		mu:     &sync.Mutex{},
+		opt:    opt,
+		rules:  make(map[lr.Level]SamplingRule),
+		counts: make(map[sampleKey]*sampleCount),
+		report: report,
+		done:   make(chan struct{}),
+	}
+	for name, rule := range opt.Levels {
+		level, err := lr.ParseLevel(name)
+		if err != nil {
+			return nil, fmt.Errorf(`invalid sampling level %q`, name)
+		}
+		s.rules[level] = rule
+	}
+	go s.run()
+	return s, nil
+}
+
+// rule returns sampling rule of level, false if level is not sampled
+func (s *sampler) rule(level lr.Level) (SamplingRule, bool) {
+	if rule, ok := s.rules[level]; ok {
+		return rule, true
+	}
+	if s.opt.First <= 0 && s.opt.Thereafter <= 0 {
+		return SamplingRule{}, false
+	}
+	return SamplingRule{First: s.opt.First, Thereafter: s.opt.Thereafter}, true
+}
+
+// allow reports whether entry is written
+func (s *sampler) allow(level lr.Level, msg string) bool {
+	if s.opt.PassErrors && level <= lr.ErrorLevel {
+		return true
+	}
+	rule, ok := s.rule(level)
+	if !ok {
+		return true
+	}
+	key := sampleKey{level: level, msg: msg}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.counts[key]
+	if !ok {
+		c = &sampleCount{}
+		s.counts[key] = c
+	}
+	c.n++
+	if c.n <= rule.First {
+		return true
+	}
+	if rule.Thereafter > 0 && (c.n-rule.First)%rule.Thereafter == 0 {
+		return true
+	}
+	c.suppressed++
+	return false
+}
+
+// run resets counters every interval and reports suppressed entries
+func (s *sampler) run() {
+	ticker := time.NewTicker(s.opt.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.mu.Lock()
+			counts := s.counts
+			s.counts = make(map[sampleKey]*sampleCount, len(counts))
+			s.mu.Unlock()
+			for key, c := range counts {
+				if c.suppressed > 0 {
+					s.report(key, c.suppressed)
+				}
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *sampler) stop() {
+	close(s.done)
+}
+
+// setSampler replaces sampler according to sampling options. New sampler is
+// swapped in before the old one stops, since entries are sampled without lock.
+func (l *logrusImpl) setSampler() {
+	var s *sampler
+	if l.opt.Sampling.Enabled {
+		var err error
+		s, err = newSampler(l.opt.Sampling, func(key sampleKey, suppressed int) {
+			// summary is written directly so it is never sampled itself
+			l.log.WithFields(lr.Fields{
+				`sampled_level`: key.level.String(),
+				`sampled_msg`:   key.msg,
+				`suppressed`:    suppressed,
+			}).Log(lr.WarnLevel, infoSampling)
+		})
+		if err != nil {
+			l.log.Panic(errors.Wrapf(err, errLogger, FAILED))
+		}
+	}
+	l.swapSampler(s)
+}
+
+// swapSampler stores s and stops previous sampler
+func (l *logrusImpl) swapSampler(s *sampler) {
+	if prev := l.sampler.Swap(s); prev != nil {
+		prev.stop()
+	}
+}
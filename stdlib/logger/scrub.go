@@ -0,0 +1,381 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	lr "github.com/sirupsen/logrus"
+
+	errors "github.com/mytoko2796/sdk-go/stdlib/error"
+)
+
+const (
+	// MaskFull replaces secrets with [REDACTED]
+	MaskFull string = `full`
+	// MaskPartial keeps KeepFirst and KeepLast characters and replaces the rest with *
+	MaskPartial string = `partial`
+	// MaskHash replaces secrets with a short sha256 so equal values can still be correlated
+	MaskHash string = `hash`
+
+	// ScrubPAN masks payment card numbers passing Luhn check
+	ScrubPAN string = `pan`
+	// ScrubEmail masks email addresses
+	ScrubEmail string = `email`
+	// ScrubPhone masks phone numbers starting with + or 0
+	ScrubPhone string = `phone`
+	// ScrubToken masks bearer/basic credentials and JWTs
+	ScrubToken string = `token`
+
+	redacted     string = `[REDACTED]`
+	scrubEnabled string = `[SCRUB]`
+
+	// maxScrubJSON bounds JSON documents decoded from a single string
+	maxScrubJSON = 8
+)
+
+var (
+	defaultScrubHeaders = []string{`Authorization`, `Proxy-Authorization`, `Cookie`, `Set-Cookie`, `X-Api-Key`}
+	defaultScrubKeys    = []string{`password`, `passwd`, `secret`, `token`, `access_token`, `refresh_token`, `cvv`, `pin`}
+
+	scrubPatterns = map[string]*regexp.Regexp{
+		ScrubPAN:   regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`),
+		ScrubEmail: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+		ScrubPhone: regexp.MustCompile(`(?:\+|\b0)\d(?:[ -]?\d){7,13}\b`),
+		ScrubToken: regexp.MustCompile(`\b(?:Bearer|Basic)\s+[A-Za-z0-9._~+/=-]{16,}|\beyJ[\w-]+\.[\w-]+\.[\w-]+`),
+	}
+	// scrubPatternOrder applies card numbers before phone numbers since they overlap
+	scrubPatternOrder = []string{ScrubToken, ScrubPAN, ScrubEmail, ScrubPhone}
+)
+
+// ScrubOptions defines masking of secrets and personal data before entries reach any output.
+// Message and fields holding string, []byte, error or fmt.Stringer are masked, other
+// field values e.g. structs or maps are written as they are.
+type ScrubOptions struct {
+	Enabled bool
+	// Headers are masked in HTTP dumps, default Authorization, Proxy-Authorization, Cookie, Set-Cookie and X-Api-Key
+	Headers []string
+	// Keys are JSON keys masked at any depth, default password, secret, token, cvv, pin and alike
+	Keys []string
+	// JSONPaths are dot separated JSON paths, * matches any key or array index e.g. card.number or items.*.cvv
+	JSONPaths []string
+	// Patterns are built-in patterns: pan, email, phone and token, default all of them
+	Patterns []string
+	// Regexps are additional patterns
+	Regexps []string
+	// Mask of pattern matches is full, partial or hash, default partial. Headers, keys
+	// and paths hold secrets so they are always redacted, or hashed with hash mask.
+	Mask string
+	// KeepFirst and KeepLast characters with partial mask, default keeps last 4
+	KeepFirst int
+	KeepLast  int
+}
+
+type scrubRule struct {
+	re    *regexp.Regexp
+	luhn  bool
+	email bool
+}
+
+// Scrubber masks secrets in strings
+type Scrubber struct {
+	opt     ScrubOptions
+	headers *regexp.Regexp
+	keys    map[string]bool
+	paths   [][]string
+	rules   []scrubRule
+}
+
+// NewScrubber returns Scrubber based on options
+func NewScrubber(opt ScrubOptions) (*Scrubber, error) {
+	if opt.Headers == nil {
+		opt.Headers = defaultScrubHeaders
+	}
+	if opt.Keys == nil {
+		opt.Keys = defaultScrubKeys
+	}
+	if opt.Patterns == nil {
+		opt.Patterns = scrubPatternOrder
+	}
+	if opt.Mask == "" {
+		opt.Mask = MaskPartial
+	}
+	if opt.Mask == MaskPartial && opt.KeepFirst == 0 && opt.KeepLast == 0 {
+		opt.KeepLast = 4
+	}
+	s := &Scrubber{
+		opt:  opt,
+		keys: make(map[string]bool, len(opt.Keys)),
+	}
+	if len(opt.Headers) > 0 {
+		names := make([]string, len(opt.Headers))
+		for i, h := range opt.Headers {
+			names[i] = regexp.QuoteMeta(h)
+		}
+		s.headers = regexp.MustCompile(`(?im)^((?:` + strings.Join(names, "|") + `)[ \t]*:[ \t]*)([^\r\n]*)`)
+	}
+	for _, k := range opt.Keys {
+		s.keys[strings.ToLower(k)] = true
+	}
+	for _, p := range opt.JSONPaths {
+		s.paths = append(s.paths, strings.Split(strings.ToLower(p), "."))
+	}
+	enabled := make(map[string]bool, len(opt.Patterns))
+	for _, p := range opt.Patterns {
+		if _, ok := scrubPatterns[p]; !ok {
+			return nil, fmt.Errorf(`unknown scrub pattern %s`, p)
+		}
+		enabled[p] = true
+	}
+	for _, p := range scrubPatternOrder {
+		if enabled[p] {
+			s.rules = append(s.rules, scrubRule{re: scrubPatterns[p], luhn: p == ScrubPAN, email: p == ScrubEmail})
+		}
+	}
+	for _, expr := range opt.Regexps {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, err
+		}
+		s.rules = append(s.rules, scrubRule{re: re})
+	}
+	return s, nil
+}
+
+// Scrub masks header values, JSON documents and patterns found in str
+func (s *Scrubber) Scrub(str string) string {
+	if s.headers != nil {
+		str = s.headers.ReplaceAllStringFunc(str, func(m string) string {
+			sub := s.headers.FindStringSubmatch(m)
+			return sub[1] + s.maskSecret(sub[2])
+		})
+	}
+	if len(s.keys) > 0 || len(s.paths) > 0 {
+		str = s.scrubJSON(str)
+	}
+	for _, r := range s.rules {
+		str = r.re.ReplaceAllStringFunc(str, func(m string) string {
+			if r.luhn && !luhn(m) {
+				return m
+			}
+			if i := strings.LastIndexByte(m, '@'); r.email && i > 0 && s.opt.Mask == MaskPartial {
+				// keep domain of email addresses
+				return s.mask(m[:i]) + m[i:]
+			}
+			return s.mask(m)
+		})
+	}
+	return str
+}
+
+// scrubJSON finds JSON objects and arrays embedded in str, e.g. HTTP bodies, and masks
+// their keys. Documents are re-encoded only if something is masked.
+func (s *Scrubber) scrubJSON(str string) string {
+	var out strings.Builder
+	last, found, attempts := 0, 0, 0
+	for i := 0; i < len(str) && found < maxScrubJSON && attempts < 8*maxScrubJSON; {
+		j := strings.IndexAny(str[i:], "{[")
+		if j < 0 {
+			break
+		}
+		start := i + j
+		attempts++
+		dec := json.NewDecoder(strings.NewReader(str[start:]))
+		dec.UseNumber()
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			i = start + 1
+			continue
+		}
+		found++
+		end := start + int(dec.InputOffset())
+		i = end
+		if !s.scrubValue(v, nil) {
+			continue
+		}
+		buf := &bytes.Buffer{}
+		enc := json.NewEncoder(buf)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(v); err != nil {
+			continue
+		}
+		out.WriteString(str[last:start])
+		out.Write(bytes.TrimRight(buf.Bytes(), "\n"))
+		last = end
+	}
+	if last == 0 {
+		return str
+	}
+	out.WriteString(str[last:])
+	return out.String()
+}
+
+// scrubValue masks matching keys and paths in place and reports whether anything is masked
+func (s *Scrubber) scrubValue(v interface{}, path []string) bool {
+	var changed bool
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, val := range t {
+			p := append(path[:len(path):len(path)], strings.ToLower(k))
+			if s.keys[strings.ToLower(k)] || s.matchPath(p) {
+				t[k] = s.maskSecret(fmt.Sprint(val))
+				changed = true
+				continue
+			}
+			changed = s.scrubValue(val, p) || changed
+		}
+	case []interface{}:
+		for i, val := range t {
+			p := append(path[:len(path):len(path)], strconv.Itoa(i))
+			if s.matchPath(p) {
+				t[i] = s.maskSecret(fmt.Sprint(val))
+				changed = true
+				continue
+			}
+			changed = s.scrubValue(val, p) || changed
+		}
+	}
+	return changed
+}
+
+func (s *Scrubber) matchPath(p []string) bool {
+	for _, pattern := range s.paths {
+		if len(pattern) != len(p) {
+			continue
+		}
+		match := true
+		for i := range pattern {
+			if pattern[i] != "*" && pattern[i] != p[i] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// maskSecret redacts secrets, partial mask would leak most of short secrets
+func (s *Scrubber) maskSecret(v string) string {
+	if s.opt.Mask == MaskHash {
+		return s.mask(v)
+	}
+	return redacted
+}
+
+// mask returns masked value according to mask format
+func (s *Scrubber) mask(v string) string {
+	switch s.opt.Mask {
+	case MaskHash:
+		sum := sha256.Sum256([]byte(v))
+		return `sha256:` + hex.EncodeToString(sum[:6])
+	case MaskPartial:
+		r := []rune(v)
+		if len(r) <= s.opt.KeepFirst+s.opt.KeepLast {
+			return strings.Repeat("*", len(r))
+		}
+		return string(r[:s.opt.KeepFirst]) + strings.Repeat("*", len(r)-s.opt.KeepFirst-s.opt.KeepLast) + string(r[len(r)-s.opt.KeepLast:])
+	default:
+		return redacted
+	}
+}
+
+// luhn validates card number check digit ignoring spaces and dashes
+func luhn(number string) bool {
+	var sum, n int
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && sum%10 == 0
+}
+
+// scrubHook masks entry message and text fields before they are formatted.
+// It is registered before other hooks so sinks only see scrubbed entries.
+type scrubHook struct {
+	mu       *sync.RWMutex
+	scrubber *Scrubber
+}
+
+func newScrubHook() *scrubHook {
+	return &scrubHook{
+		mu: &sync.RWMutex{},
+	}
+}
+
+func (h *scrubHook) set(s *Scrubber) {
+	h.mu.Lock()
+	h.scrubber = s
+	h.mu.Unlock()
+}
+
+func (h *scrubHook) Levels() []lr.Level {
+	return lr.AllLevels
+}
+
+func (h *scrubHook) Fire(e *lr.Entry) error {
+	h.mu.RLock()
+	s := h.scrubber
+	h.mu.RUnlock()
+	if s == nil {
+		return nil
+	}
+	e.Message = s.Scrub(e.Message)
+	for k, v := range e.Data {
+		if scrubbed, ok := scrubValue(s, v); ok {
+			e.Data[k] = scrubbed
+		}
+	}
+	return nil
+}
+
+// scrubValue returns scrubbed text of v. Error and fmt.Stringer are replaced by
+// their scrubbed text only if it differs, so unmasked values keep their type.
+func scrubValue(s *Scrubber, v interface{}) (interface{}, bool) {
+	switch t := v.(type) {
+	case string:
+		return s.Scrub(t), true
+	case []byte:
+		return []byte(s.Scrub(string(t))), true
+	case error, fmt.Stringer:
+		// fmt recovers from nil pointer receivers
+		text := fmt.Sprint(t)
+		if scrubbed := s.Scrub(text); scrubbed != text {
+			return scrubbed, true
+		}
+	}
+	return nil, false
+}
+
+// convertAndSetScrubber replaces scrubber according to options
+func (l *logrusImpl) convertAndSetScrubber() {
+	if !l.opt.Scrub.Enabled {
+		l.scrub.set(nil)
+		return
+	}
+	s, err := NewScrubber(l.opt.Scrub)
+	if err != nil {
+		l.log.Panic(errors.Wrapf(err, errLogger, FAILED))
+	}
+	l.scrub.set(s)
+	l.log.Info(OK, infoLogger, scrubEnabled)
+}
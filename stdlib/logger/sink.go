@@ -0,0 +1,265 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	lr "github.com/sirupsen/logrus"
+
+	errors "github.com/mytoko2796/sdk-go/stdlib/error"
+)
+
+const (
+	SinkStdout   string = `stdout`
+	SinkFile     string = `file`
+	SinkSyslog   string = `syslog`
+	SinkJournald string = `journald`
+	SinkHTTP     string = `http`
+
+	sinkCustom string = `custom`
+	infoSink   string = `Logger Sink:`
+)
+
+var (
+	errUnknownSink = fmt.Errorf(`Unknown log Sink`)
+	ErrUnknownSink = errors.Wrapf(errUnknownSink, errLogger, FAILED)
+)
+
+// Sink receives entries formatted by the sink formatter in addition to logger output.
+// Level is one of Level* constants. Custom sinks (e.g. Kafka producer) only need to
+// implement this interface.
+type Sink interface {
+	Write(level string, p []byte) error
+	Close() error
+}
+
+// Flusher is implemented by sinks buffering entries, Flush is called before the process exits
+type Flusher interface {
+	Flush() error
+}
+
+// SinkOptions defines an additional output with its own level and formatter
+type SinkOptions struct {
+	// Type defines built-in sink: stdout, file, syslog, journald or http. It is ignored if Sink is set
+	Type string
+	// Sink is a custom sink
+	Sink Sink
+	// Level is the least severe level written to the sink, default writes everything the logger writes
+	Level string
+	// Formatter of the sink, default json
+	Formatter string
+	// FormatterOptions customizes the sink formatter
+	FormatterOptions FormatterOptions
+	// Async writes to the sink from a background goroutine
+	Async AsyncOptions
+	// Path and File define file sink
+	Path string
+	File FileOptions
+	// Syslog defines syslog sink
+	Syslog SyslogOptions
+	// Journald defines journald sink
+	Journald JournaldOptions
+	// HTTP defines batched http push sink
+	HTTP HTTPSinkOptions
+}
+
+// levelName returns Level* constant of logrus level
+func levelName(level lr.Level) string {
+	if level == lr.WarnLevel {
+		return LevelWarn
+	}
+	return level.String()
+}
+
+// writerSink writes entries to io.Writer
+type writerSink struct {
+	w io.WriteCloser
+}
+
+// NewWriterSink returns Sink writing to w. Close closes w if it is an io.Closer.
+func NewWriterSink(w io.Writer) Sink {
+	if wc, ok := w.(io.WriteCloser); ok {
+		return &writerSink{w: wc}
+	}
+	return &writerSink{w: nopCloser{w}}
+}
+
+func (s *writerSink) Write(_ string, p []byte) error {
+	_, err := s.w.Write(p)
+	return err
+}
+
+func (s *writerSink) Close() error {
+	return s.w.Close()
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+// newSink returns sink defined by options
+func newSink(opt SinkOptions) (Sink, error) {
+	if opt.Sink != nil {
+		return opt.Sink, nil
+	}
+	switch opt.Type {
+	case SinkStdout:
+		return NewWriterSink(nopCloser{os.Stdout}), nil
+	case SinkFile:
+		w, err := newRotateWriter(opt.Path, opt.File)
+		if err != nil {
+			return nil, errors.Wrapf(err, errLogger, FAILED)
+		}
+		return NewWriterSink(w), nil
+	case SinkSyslog:
+		return NewSyslogSink(opt.Syslog)
+	case SinkJournald:
+		return NewJournaldSink(opt.Journald)
+	case SinkHTTP:
+		return NewHTTPSink(opt.HTTP)
+	default:
+		return nil, ErrUnknownSink
+	}
+}
+
+// sinkOutput is a sink with its level and formatter
+type sinkOutput struct {
+	sink      Sink
+	level     lr.Level
+	formatter lr.Formatter
+	async     *asyncWriter
+}
+
+func newSinkOutput(opt SinkOptions) (*sinkOutput, error) {
+	if opt.Formatter == "" {
+		opt.Formatter = FormatJSON
+	}
+	level := lr.TraceLevel
+	if opt.Level != "" {
+		lvl, err := parseLevel(opt.Level)
+		if err != nil {
+			return nil, err
+		}
+		level = lvl
+	}
+	formatter, err := newFormatter(opt.Formatter, opt.FormatterOptions)
+	if err != nil {
+		return nil, err
+	}
+	sink, err := newSink(opt)
+	if err != nil {
+		return nil, err
+	}
+	s := &sinkOutput{
+		sink:      sink,
+		level:     level,
+		formatter: formatter,
+	}
+	if opt.Async.Enabled {
+		s.async = newAsyncWriter(s.write, opt.Async)
+	}
+	return s, nil
+}
+
+func (s *sinkOutput) write(level lr.Level, p []byte) error {
+	return s.sink.Write(levelName(level), p)
+}
+
+func (s *sinkOutput) close() error {
+	if s.async != nil {
+		s.async.Close()
+	}
+	return s.sink.Close()
+}
+
+// sinkHook fans out entries to sinks
+type sinkHook struct {
+	mu      *sync.RWMutex
+	outputs []*sinkOutput
+}
+
+func newSinkHook() *sinkHook {
+	return &sinkHook{
+		mu: &sync.RWMutex{},
+	}
+}
+
+func (h *sinkHook) Levels() []lr.Level {
+	return lr.AllLevels
+}
+
+// Fire writes entry to every sink accepting its level. Sink errors are written to
+// stderr so a failing sink does not stop the others.
+func (h *sinkHook) Fire(e *lr.Entry) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, s := range h.outputs {
+		if e.Level > s.level {
+			continue
+		}
+		p, err := s.formatter.Format(e)
+		if err == nil {
+			if s.async != nil {
+				s.async.writeLevel(e.Level, p)
+				continue
+			}
+			err = s.write(e.Level, p)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, errors.Wrapf(err, errLogger, FAILED))
+		}
+	}
+	return nil
+}
+
+// set replaces sinks and closes previous ones
+func (h *sinkHook) set(outputs []*sinkOutput) {
+	h.mu.Lock()
+	prev := h.outputs
+	h.outputs = outputs
+	h.mu.Unlock()
+	for _, s := range prev {
+		s.close()
+	}
+}
+
+func (h *sinkHook) flush() {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, s := range h.outputs {
+		if s.async != nil {
+			s.async.Flush()
+		}
+		if f, ok := s.sink.(Flusher); ok {
+			f.Flush()
+		}
+	}
+}
+
+// convertAndSetSinks replaces sinks according to options
+func (l *logrusImpl) convertAndSetSinks() {
+	var outputs []*sinkOutput
+	for _, opt := range l.opt.Sinks {
+		s, err := newSinkOutput(opt)
+		if err != nil {
+			for _, prev := range outputs {
+				prev.close()
+			}
+			l.log.Panic(err)
+		}
+		outputs = append(outputs, s)
+		name := opt.Type
+		if opt.Sink != nil {
+			name = sinkCustom
+		}
+		l.log.Info(OK, infoSink, fmt.Sprintf("[%s]", strings.ToUpper(name)))
+	}
+	l.sinks.set(outputs)
+}
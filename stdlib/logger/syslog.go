@@ -0,0 +1,173 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	errors "github.com/mytoko2796/sdk-go/stdlib/error"
+)
+
+const (
+	// syslog facility local0
+	defaultSyslogFacility = 16
+	// maximum length of APP-NAME in RFC5424
+	syslogAppNameMax = 48
+	syslogNilValue   = `-`
+)
+
+var (
+	// syslogSeverity maps Level* constants to RFC5424 severity
+	syslogSeverity = map[string]int{
+		LevelPanic: 0, // emergency
+		LevelFatal: 2, // critical
+		LevelError: 3,
+		LevelWarn:  4,
+		LevelInfo:  6,
+		LevelDebug: 7,
+		LevelTrace: 7,
+	}
+)
+
+// SyslogOptions defines RFC5424 syslog sink
+type SyslogOptions struct {
+	// Network is udp, tcp or unix
+	Network string
+	// Address e.g. localhost:514 or /dev/log
+	Address string
+	// Facility number, default 16 (local0)
+	Facility int
+	// AppName default is executable name
+	AppName string
+	// Hostname default is os hostname
+	Hostname string
+	// Timeout of dial and write, default 5s
+	Timeout time.Duration
+}
+
+// syslogSink writes RFC5424 messages. Stream transports use octet counting framing (RFC6587).
+type syslogSink struct {
+	mu      *sync.Mutex
+	opt     SyslogOptions
+	network string
+	conn    net.Conn
+	stream  bool
+	procID  string
+}
+
+// NewSyslogSink returns Sink writing RFC5424 messages to syslog server
+func NewSyslogSink(opt SyslogOptions) (Sink, error) {
+	if opt.Facility == 0 {
+		opt.Facility = defaultSyslogFacility
+	}
+	if opt.AppName == "" {
+		opt.AppName = filepath.Base(os.Args[0])
+	}
+	if len(opt.AppName) > syslogAppNameMax {
+		opt.AppName = opt.AppName[:syslogAppNameMax]
+	}
+	if opt.Hostname == "" {
+		opt.Hostname, _ = os.Hostname()
+	}
+	if opt.Hostname == "" {
+		opt.Hostname = syslogNilValue
+	}
+	if opt.Timeout <= 0 {
+		opt.Timeout = defaultSinkTimeout
+	}
+	s := &syslogSink{
+		mu:     &sync.Mutex{},
+		opt:    opt,
+		procID: fmt.Sprint(os.Getpid()),
+	}
+	if err := s.connect(); err != nil {
+		return nil, errors.Wrap(err, `cannot connect to syslog %s %s`, opt.Network, opt.Address)
+	}
+	return s, nil
+}
+
+// connect dials syslog, unix sockets are tried as datagram first then as stream
+func (s *syslogSink) connect() error {
+	switch s.opt.Network {
+	case "unix":
+		conn, err := net.DialTimeout("unixgram", s.opt.Address, s.opt.Timeout)
+		if err == nil {
+			s.conn, s.stream = conn, false
+			return nil
+		}
+		conn, err = net.DialTimeout("unix", s.opt.Address, s.opt.Timeout)
+		if err != nil {
+			return err
+		}
+		s.conn, s.stream = conn, true
+	case "udp", "tcp":
+		conn, err := net.DialTimeout(s.opt.Network, s.opt.Address, s.opt.Timeout)
+		if err != nil {
+			return err
+		}
+		s.conn, s.stream = conn, s.opt.Network == "tcp"
+	default:
+		return fmt.Errorf(`unsupported syslog network %s`, s.opt.Network)
+	}
+	return nil
+}
+
+// format returns <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID SD MSG
+func (s *syslogSink) format(level string, p []byte) []byte {
+	severity, ok := syslogSeverity[level]
+	if !ok {
+		severity = syslogSeverity[LevelInfo]
+	}
+	msg := bytes.TrimRight(p, "\n")
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "<%d>1 %s %s %s %s %s %s ",
+		s.opt.Facility*8+severity,
+		time.Now().Format(time.RFC3339Nano),
+		s.opt.Hostname, s.opt.AppName, s.procID, syslogNilValue, syslogNilValue)
+	buf.Write(msg)
+	if !s.stream {
+		return buf.Bytes()
+	}
+	framed := &bytes.Buffer{}
+	fmt.Fprintf(framed, "%d ", buf.Len())
+	framed.Write(buf.Bytes())
+	return framed.Bytes()
+}
+
+// Write sends message and reconnects once if connection is broken
+func (s *syslogSink) Write(level string, p []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	msg := s.format(level, p)
+	if s.conn != nil {
+		s.conn.SetWriteDeadline(time.Now().Add(s.opt.Timeout))
+		if _, err := s.conn.Write(msg); err == nil {
+			return nil
+		}
+		s.conn.Close()
+		s.conn = nil
+	}
+	if err := s.connect(); err != nil {
+		return err
+	}
+	// framing depends on transport picked by connect
+	msg = s.format(level, p)
+	s.conn.SetWriteDeadline(time.Now().Add(s.opt.Timeout))
+	_, err := s.conn.Write(msg)
+	return err
+}
+
+func (s *syslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
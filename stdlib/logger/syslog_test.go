@@ -0,0 +1,150 @@
+package logger
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestSyslogSink(t *testing.T, network, address string) Sink {
+	t.Helper()
+	s, err := NewSyslogSink(SyslogOptions{
+		Network:  network,
+		Address:  address,
+		AppName:  "app",
+		Hostname: "host",
+		Timeout:  time.Second,
+	})
+	if err != nil {
+		t.Fatalf("NewSyslogSink() error = %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// checkSyslogMessage checks RFC5424 header and message of datagram or unframed stream message
+func checkSyslogMessage(t *testing.T, got string, pri int, msg string) {
+	t.Helper()
+	// <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID SD MSG
+	parts := strings.SplitN(got, " ", 8)
+	if len(parts) != 8 {
+		t.Fatalf("message %q has %d parts, want 8", got, len(parts))
+	}
+	if want := fmt.Sprintf("<%d>1", pri); parts[0] != want {
+		t.Errorf("PRI and VERSION = %q, want %q", parts[0], want)
+	}
+	if _, err := time.Parse(time.RFC3339Nano, parts[1]); err != nil {
+		t.Errorf("TIMESTAMP %q is not RFC3339: %v", parts[1], err)
+	}
+	if parts[2] != "host" || parts[3] != "app" {
+		t.Errorf("HOSTNAME APP-NAME = %q %q, want host app", parts[2], parts[3])
+	}
+	if parts[5] != syslogNilValue || parts[6] != syslogNilValue {
+		t.Errorf("MSGID SD = %q %q, want nil values", parts[5], parts[6])
+	}
+	if parts[7] != msg {
+		t.Errorf("MSG = %q, want %q", parts[7], msg)
+	}
+}
+
+func TestSyslogSinkUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	s := newTestSyslogSink(t, "udp", conn.LocalAddr().String())
+
+	if err := s.Write(LevelError, []byte("boom\n")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	buf := make([]byte, 2048)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// local0 * 8 + error
+	checkSyslogMessage(t, string(buf[:n]), 16*8+3, "boom")
+}
+
+func TestSyslogSinkTCPOctetCounting(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			accepted <- conn
+		}
+	}()
+	s := newTestSyslogSink(t, "tcp", ln.Addr().String())
+
+	msgs := []string{"first", "second entry"}
+	for _, msg := range msgs {
+		if err := s.Write(LevelWarn, []byte(msg+"\n")); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	var conn net.Conn
+	select {
+	case conn = <-accepted:
+	case <-time.After(time.Second):
+		t.Fatal("syslog sink did not connect")
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	r := bufio.NewReader(conn)
+	for _, msg := range msgs {
+		length, err := r.ReadString(' ')
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(length, " "))
+		if err != nil {
+			t.Fatalf("frame length %q: %v", length, err)
+		}
+		frame := make([]byte, n)
+		if _, err := io.ReadFull(r, frame); err != nil {
+			t.Fatal(err)
+		}
+		checkSyslogMessage(t, string(frame), 16*8+4, msg)
+	}
+}
+
+func TestSyslogSinkUnixDatagram(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	s := newTestSyslogSink(t, "unix", path)
+
+	if err := s.Write(LevelDebug, []byte("debug entry")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	buf := make([]byte, 2048)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// datagrams are not framed
+	checkSyslogMessage(t, string(buf[:n]), 16*8+7, "debug entry")
+}
+
+func TestSyslogSinkUnsupportedNetwork(t *testing.T) {
+	if _, err := NewSyslogSink(SyslogOptions{Network: "sctp", Address: "localhost:514"}); err == nil {
+		t.Fatal("NewSyslogSink() error = nil, want unsupported network error")
+	}
+}
@@ -0,0 +1,114 @@
+package logger
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+
+	errors "github.com/mytoko2796/sdk-go/stdlib/error"
+	lr "github.com/sirupsen/logrus"
+	"go.opencensus.io/trace"
+)
+
+const (
+	// TraceFormatDefault adds trace_id, span_id and sampled fields in hex
+	TraceFormatDefault string = `default`
+	// TraceFormatDatadog adds dd.trace_id and dd.span_id in decimal as expected by Datadog log-trace linking
+	TraceFormatDatadog string = `datadog`
+	// TraceFormatStackdriver adds logging.googleapis.com trace fields as expected by Cloud Logging
+	TraceFormatStackdriver string = `stackdriver`
+	// TraceFormatECS adds trace.id and span.id fields of Elastic Common Schema
+	TraceFormatECS string = `ecs`
+)
+
+var (
+	errNoProjectID = fmt.Errorf(`stackdriver trace format requires ProjectID, default format is used`)
+	ErrNoProjectID = errors.Wrapf(errNoProjectID, errLogger, FAILED)
+)
+
+// TraceOptions defines fields added from OpenCensus span found in context
+type TraceOptions struct {
+	// Format defines field names and value encoding: default, datadog, stackdriver or ecs
+	Format string
+	// ProjectID is required by stackdriver format to build trace resource name,
+	// default format is used without it
+	ProjectID string
+	// TraceIDKey, SpanIDKey and SampledKey override field names of the format
+	TraceIDKey string
+	SpanIDKey  string
+	SampledKey string
+}
+
+type traceKeys struct {
+	traceID string
+	spanID  string
+	sampled string
+}
+
+var traceFormatKeys = map[string]traceKeys{
+	TraceFormatDefault:     {traceID: `trace_id`, spanID: `span_id`, sampled: `sampled`},
+	TraceFormatDatadog:     {traceID: `dd.trace_id`, spanID: `dd.span_id`, sampled: `dd.sampled`},
+	TraceFormatStackdriver: {traceID: `logging.googleapis.com/trace`, spanID: `logging.googleapis.com/spanId`, sampled: `logging.googleapis.com/trace_sampled`},
+	TraceFormatECS:         {traceID: `trace.id`, spanID: `span.id`, sampled: `trace.sampled`},
+}
+
+// keys returns field names of the format overridden by custom keys
+func (o TraceOptions) keys() traceKeys {
+	k, ok := traceFormatKeys[o.Format]
+	if !ok {
+		k = traceFormatKeys[TraceFormatDefault]
+	}
+	if o.TraceIDKey != "" {
+		k.traceID = o.TraceIDKey
+	}
+	if o.SpanIDKey != "" {
+		k.spanID = o.SpanIDKey
+	}
+	if o.SampledKey != "" {
+		k.sampled = o.SampledKey
+	}
+	return k
+}
+
+// contextOptions holds options read by *WithContext methods, it is swapped as a whole
+// by SetOptions since entries read it without lock
+type contextOptions struct {
+	fields map[string]string
+	trace  TraceOptions
+}
+
+// setContextOptions stores context fields and trace options. Stackdriver format without
+// ProjectID builds trace names Cloud Logging cannot link, so default format is used instead.
+func (l *logrusImpl) setContextOptions() {
+	opt := &contextOptions{fields: l.opt.ContextFields, trace: l.opt.Trace}
+	if opt.trace.Format == TraceFormatStackdriver && opt.trace.ProjectID == "" {
+		l.log.Error(ErrNoProjectID)
+		opt.trace.Format = TraceFormatDefault
+	}
+	l.ctxOptions.Store(opt)
+}
+
+// traceFields returns span correlation fields if context carries OpenCensus span
+func (o TraceOptions) traceFields(ctx context.Context) lr.Fields {
+	span := trace.FromContext(ctx)
+	if span == nil {
+		return nil
+	}
+	sc := span.SpanContext()
+	k := o.keys()
+	fields := lr.Fields{k.sampled: sc.IsSampled()}
+	switch o.Format {
+	case TraceFormatDatadog:
+		// datadog uses lower 64 bits of trace id
+		fields[k.traceID] = strconv.FormatUint(binary.BigEndian.Uint64(sc.TraceID[8:]), 10)
+		fields[k.spanID] = strconv.FormatUint(binary.BigEndian.Uint64(sc.SpanID[:]), 10)
+	case TraceFormatStackdriver:
+		fields[k.traceID] = fmt.Sprintf("projects/%s/traces/%s", o.ProjectID, sc.TraceID)
+		fields[k.spanID] = sc.SpanID.String()
+	default:
+		fields[k.traceID] = sc.TraceID.String()
+		fields[k.spanID] = sc.SpanID.String()
+	}
+	return fields
+}
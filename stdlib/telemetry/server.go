@@ -70,19 +70,25 @@ func (t *telemetry) GetServers() []*Server {
 	return servers
 }
 
-func (t *telemetry) Shutdown() {
+func (t *telemetry) Shutdown(ctx context.Context) error {
 	t.termSig <- struct{}{}
 	t.exp.StopAllStats()
 	t.exp.StopAllTracers()
 	t.exp.StopAllProfilers()
+	var result error
 	for _, s := range t.servers {
 		if s != nil {
-			if err := s.Shutdown(context.Background()); err != nil {
-				err = errors.Wrap(err, errTeleServe)
-				s.ErrorLog.Fatal(err)
+			if err := s.Shutdown(ctx); err != nil {
+				s.Close()
+				err = errors.Wrap(err, errTeleServe, FAILED, s.Addr)
+				t.logger.Error(err)
+				if result == nil {
+					result = err
+				}
 			}
 		}
 	}
+	return result
 }
 
 func (t *telemetry) NewServer(handler http.Handler, address string, readHeaderTimeout, readTimeout, writeTimeout time.Duration) *http.Server {
@@ -0,0 +1,10 @@
+package stat
+
+import (
+	"go.opencensus.io/stats"
+)
+
+var (
+	// Remote Config Stat
+	StatConfigCacheAge = stats.Int64(`go.config/cache_age`, `Age of last-known-good remote configuration served from local cache in seconds. 0 means live source is used`, stats.UnitSeconds)
+)
@@ -0,0 +1,8 @@
+package stat
+
+import "go.opencensus.io/stats"
+
+var (
+	StatGraceUpgrade        = stats.Int64(`go.grace/upgrade`, `Number of upgrades by result`, stats.UnitDimensionless)
+	StatGraceUpgradeLatency = stats.Float64(`go.grace/upgrade_latency`, `Time until new process is ready or upgrade fails in milliseconds`, stats.UnitMilliseconds)
+)
@@ -0,0 +1,10 @@
+package stat
+
+import "go.opencensus.io/stats"
+
+var (
+	StatHealthProbeStatus     = stats.Int64(`go.health/probe_status`, `Probe status, 1 if probe passes otherwise 0`, stats.UnitDimensionless)
+	StatHealthProbeTransition = stats.Int64(`go.health/probe_transition`, `Number of probe status transitions`, stats.UnitDimensionless)
+	StatHealthCheckStatus     = stats.Int64(`go.health/check_status`, `Check status, 1 if check passes otherwise 0`, stats.UnitDimensionless)
+	StatHealthCheckLatency    = stats.Float64(`go.health/check_latency`, `Latency of health check in milliseconds`, stats.UnitMilliseconds)
+)
@@ -4,4 +4,5 @@ import "go.opencensus.io/stats"
 
 var (
 	StatLoggerLogCount = stats.Int64(`go.logger/count`, `Current number of log messages by level`, stats.UnitDimensionless)
+	StatLoggerDropped  = stats.Int64(`go.logger/dropped`, `Number of log messages dropped by async output`, stats.UnitDimensionless)
 )
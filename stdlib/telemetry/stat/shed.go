@@ -0,0 +1,9 @@
+package stat
+
+import "go.opencensus.io/stats"
+
+var (
+	StatShedCount      = stats.Int64(`go.http.server/shed`, `Number of requests rejected by load shedding`, stats.UnitDimensionless)
+	StatShedQueueDelay = stats.Float64(`go.http.server/queue_delay`, `Time request waited for a concurrency slot in milliseconds`, stats.UnitMilliseconds)
+	StatShedInFlight   = stats.Int64(`go.http.server/in_flight`, `Number of requests being served`, stats.UnitDimensionless)
+)
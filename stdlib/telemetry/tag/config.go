@@ -0,0 +1,7 @@
+package tag
+
+import tags "go.opencensus.io/tag"
+
+var (
+	TagConfigSource, _ = tags.NewKey(`go.config.source`)
+)
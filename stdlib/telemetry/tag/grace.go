@@ -0,0 +1,7 @@
+package tag
+
+import tags "go.opencensus.io/tag"
+
+var (
+	TagGraceResult, _ = tags.NewKey(`grace.result`)
+)
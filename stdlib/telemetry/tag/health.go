@@ -0,0 +1,9 @@
+package tag
+
+import tags "go.opencensus.io/tag"
+
+var (
+	TagHealthProbe, _  = tags.NewKey(`health.probe`)
+	TagHealthCheck, _  = tags.NewKey(`health.check`)
+	TagHealthStatus, _ = tags.NewKey(`health.status`)
+)
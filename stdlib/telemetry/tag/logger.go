@@ -7,4 +7,6 @@ import (
 var (
 	//client tag
 	TagLoggerLogLevel, _ = tags.NewKey(`log.level`)
+	// component is the name of named logger e.g. sql
+	TagLoggerComponent, _ = tags.NewKey(`log.component`)
 )
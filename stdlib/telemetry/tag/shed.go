@@ -0,0 +1,7 @@
+package tag
+
+import tags "go.opencensus.io/tag"
+
+var (
+	TagShedReason, _ = tags.NewKey(`http.shed.reason`)
+)
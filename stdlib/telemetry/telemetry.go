@@ -1,6 +1,7 @@
 package telemetry
 
 import (
+	"context"
 	"net"
 	"net/http"
 	"time"
@@ -11,8 +12,6 @@ import (
 	"github.com/mytoko2796/sdk-go/stdlib/telemetry/view"
 
 	"go.opencensus.io/plugin/ochttp"
-
-	"sync"
 )
 
 const (
@@ -35,7 +34,6 @@ const (
 )
 
 var (
-	once   = sync.Once{}
 	server = []string{
 		idxMetric:  "[METRIC]",
 		idxTracing: "[TRACING]",
@@ -50,7 +48,8 @@ type Telemetry interface {
 
 	Serve(mode int, ln net.Listener)
 	GetServers() []*Server
-	Shutdown()
+	// Shutdown stops exporters and servers, it waits for in-flight requests until ctx is done
+	Shutdown(ctx context.Context) error
 }
 
 type telemetry struct {
@@ -71,28 +70,29 @@ type Server struct {
 	Addr string
 }
 
+// Init returns Telemetry, it must be called once per process. Instances are not independent
+// since they share global OpenCensus state: a second Init registers exporters and views again,
+// starts another goroutine recording gauge.MRegistry and Shutdown of either instance stops
+// exporters used by the other.
 func Init(logger log.Logger, opt Options) Telemetry {
-	var t *telemetry
-	once.Do(func() {
-		t = &telemetry{
-			logger:  logger,
-			opt:     opt,
-			servers: make([]*http.Server, len(server)), // 0: metrics, 1: tracing << tracing will not use any http server
-			exp:     exporter.Init(logger, opt.Exporters),
-			termSig: make(chan struct{}, 1),
-		}
-		//telemetry server
-		t.initTelemetryServer()
-
-		//registering default components
-		if err := gauge.Init(opt.Exporters.Stats, t.termSig); err != nil {
-			logger.Fatal(err)
-		}
-
-		if err := view.Init(); err != nil {
-			logger.Fatal(err)
-		}
-	})
+	t := &telemetry{
+		logger:  logger,
+		opt:     opt,
+		servers: make([]*http.Server, len(server)), // 0: metrics, 1: tracing << tracing will not use any http server
+		exp:     exporter.Init(logger, opt.Exporters),
+		termSig: make(chan struct{}, 1),
+	}
+	//telemetry server
+	t.initTelemetryServer()
+
+	//registering default components
+	if err := gauge.Init(opt.Exporters.Stats, t.termSig); err != nil {
+		logger.Fatal(err)
+	}
+
+	if err := view.Init(); err != nil {
+		logger.Fatal(err)
+	}
 
 	return t
 }
@@ -0,0 +1,28 @@
+package view
+
+import (
+	"github.com/mytoko2796/sdk-go/stdlib/telemetry/stat"
+	"github.com/mytoko2796/sdk-go/stdlib/telemetry/tag"
+	"go.opencensus.io/stats/view"
+	tags "go.opencensus.io/tag"
+)
+
+var (
+	ConfigCacheAgeView = &view.View{
+		Name:        "go.config/cache_age",
+		Description: "Age of remote configuration served from local cache",
+		Measure:     stat.StatConfigCacheAge,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tags.Key{tag.TagConfigSource},
+	}
+)
+
+func overrideConfigView() {
+
+}
+
+func initConfigView() []*view.View {
+	return []*view.View{
+		ConfigCacheAgeView,
+	}
+}
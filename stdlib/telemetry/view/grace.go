@@ -0,0 +1,36 @@
+package view
+
+import (
+	"github.com/mytoko2796/sdk-go/stdlib/telemetry/stat"
+	"github.com/mytoko2796/sdk-go/stdlib/telemetry/tag"
+	"go.opencensus.io/stats/view"
+	tags "go.opencensus.io/tag"
+)
+
+var (
+	GraceUpgradeView = &view.View{
+		Name:        "go.grace/upgrade",
+		Description: "Count of upgrades by result",
+		Measure:     stat.StatGraceUpgrade,
+		Aggregation: view.Count(),
+		TagKeys:     []tags.Key{tag.TagGraceResult},
+	}
+	GraceUpgradeLatencyView = &view.View{
+		Name:        "go.grace/upgrade_latency",
+		Description: "Latency distribution of upgrades by result",
+		Measure:     stat.StatGraceUpgradeLatency,
+		Aggregation: DefaultHTTPMsDistribution,
+		TagKeys:     []tags.Key{tag.TagGraceResult},
+	}
+)
+
+func overrideGraceView() {
+
+}
+
+func initGraceView() []*view.View {
+	return []*view.View{
+		GraceUpgradeView,
+		GraceUpgradeLatencyView,
+	}
+}
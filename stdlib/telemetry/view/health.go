@@ -0,0 +1,52 @@
+package view
+
+import (
+	"github.com/mytoko2796/sdk-go/stdlib/telemetry/stat"
+	"github.com/mytoko2796/sdk-go/stdlib/telemetry/tag"
+	"go.opencensus.io/stats/view"
+	tags "go.opencensus.io/tag"
+)
+
+var (
+	HealthProbeStatusView = &view.View{
+		Name:        "go.health/probe_status",
+		Description: "Probe status, 1 if probe passes",
+		Measure:     stat.StatHealthProbeStatus,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tags.Key{tag.TagHealthProbe},
+	}
+	HealthProbeTransitionView = &view.View{
+		Name:        "go.health/probe_transition",
+		Description: "Count of probe transitions by new status",
+		Measure:     stat.StatHealthProbeTransition,
+		Aggregation: view.Count(),
+		TagKeys:     []tags.Key{tag.TagHealthProbe, tag.TagHealthStatus},
+	}
+	HealthCheckStatusView = &view.View{
+		Name:        "go.health/check_status",
+		Description: "Check status, 1 if check passes",
+		Measure:     stat.StatHealthCheckStatus,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tags.Key{tag.TagHealthCheck},
+	}
+	HealthCheckLatencyView = &view.View{
+		Name:        "go.health/check_latency",
+		Description: "Latency distribution of health checks",
+		Measure:     stat.StatHealthCheckLatency,
+		Aggregation: DefaultHTTPMsDistribution,
+		TagKeys:     []tags.Key{tag.TagHealthCheck, tag.TagHealthStatus},
+	}
+)
+
+func overrideHealthView() {
+
+}
+
+func initHealthView() []*view.View {
+	return []*view.View{
+		HealthProbeStatusView,
+		HealthProbeTransitionView,
+		HealthCheckStatusView,
+		HealthCheckLatencyView,
+	}
+}
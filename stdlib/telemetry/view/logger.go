@@ -13,6 +13,13 @@ var (
 		Description: "Count of logs by level",
 		Measure:     stat.StatLoggerLogCount,
 		Aggregation: view.Count(),
+		TagKeys:     []tags.Key{tag.TagLoggerLogLevel, tag.TagLoggerComponent},
+	}
+	LoggerDroppedView = &view.View{
+		Name:        "go.logger/dropped",
+		Description: "Count of logs dropped by async output by level",
+		Measure:     stat.StatLoggerDropped,
+		Aggregation: view.Sum(),
 		TagKeys:     []tags.Key{tag.TagLoggerLogLevel},
 	}
 )
@@ -24,5 +31,6 @@ func overrideLoggerView() {
 func initLoggerView() []*view.View {
 	return []*view.View{
 		LoggerLogCountView,
+		LoggerDroppedView,
 	}
 }
@@ -0,0 +1,43 @@
+package view
+
+import (
+	"github.com/mytoko2796/sdk-go/stdlib/telemetry/stat"
+	"github.com/mytoko2796/sdk-go/stdlib/telemetry/tag"
+	"go.opencensus.io/stats/view"
+	tags "go.opencensus.io/tag"
+)
+
+var (
+	ShedCountView = &view.View{
+		Name:        "go.http.server/shed",
+		Description: "Count of requests rejected by load shedding by reason",
+		Measure:     stat.StatShedCount,
+		Aggregation: view.Count(),
+		TagKeys:     []tags.Key{tag.TagKeyServerRoute, tag.TagShedReason},
+	}
+	ShedQueueDelayView = &view.View{
+		Name:        "go.http.server/queue_delay",
+		Description: "Queue delay distribution of requests waiting for a concurrency slot",
+		Measure:     stat.StatShedQueueDelay,
+		Aggregation: DefaultHTTPMsDistribution,
+		TagKeys:     []tags.Key{tag.TagKeyServerRoute},
+	}
+	ShedInFlightView = &view.View{
+		Name:        "go.http.server/in_flight",
+		Description: "Number of requests being served",
+		Measure:     stat.StatShedInFlight,
+		Aggregation: view.LastValue(),
+	}
+)
+
+func overrideShedView() {
+
+}
+
+func initShedView() []*view.View {
+	return []*view.View{
+		ShedCountView,
+		ShedQueueDelayView,
+		ShedInFlightView,
+	}
+}
@@ -24,6 +24,10 @@ func init() {
 	overrideOrchView()
 	overrideNotifierView()
 	overrideStorageView()
+	overrideConfigView()
+	overrideHealthView()
+	overrideShedView()
+	overrideGraceView()
 }
 
 func Init() error {
@@ -40,6 +44,10 @@ func Init() error {
 	views = append(views, initOrchView()...)
 	views = append(views, initNotifierView()...)
 	views = append(views, initStorageView()...)
+	views = append(views, initConfigView()...)
+	views = append(views, initHealthView()...)
+	views = append(views, initShedView()...)
+	views = append(views, initGraceView()...)
 	if err := view.Register(views...); err != nil {
 		return errors.Wrap(err, errRegisterDefaultView)
 	}